@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"gic/internal/llm"
+)
+
+// Exit codes of gic. Scripts, hooks and CI jobs can rely on them to react to each kind of failure.
+const (
+	ExitGeneric         = 1
+	ExitConfig          = 2
+	ExitGit             = 3
+	ExitLLM             = 4
+	ExitNoChanges       = 5
+	ExitContextTooLarge = 6
+	ExitContentFiltered = 7
+	ExitLint            = 8
+)
+
+// exitError marks an error with the exit code gic ends with when it is returned by a command.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// withExitCode marks err with the exit code. It returns nil when err is nil.
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+// ExitCode returns the exit code for an error returned by Execute.
+func ExitCode(err error) int {
+	var exitErr *exitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, llm.ErrNoChanges):
+		return ExitNoChanges
+	case errors.Is(err, llm.ErrContextTooLarge):
+		return ExitContextTooLarge
+	case errors.Is(err, llm.ErrContentFiltered):
+		return ExitContentFiltered
+	case errors.As(err, &exitErr):
+		return exitErr.code
+	default:
+		return ExitGeneric
+	}
+}
+
+// HandleError writes a readable message for an error returned by Execute to w and returns its exit code.
+func HandleError(w io.Writer, err error) int {
+	code := ExitCode(err)
+	fmt.Fprintf(w, "gic: %v\n", err)
+	if !verbose && code != ExitNoChanges {
+		fmt.Fprintln(w, "Run again with --verbose for more details.")
+	}
+	return code
+}
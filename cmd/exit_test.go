@@ -0,0 +1,74 @@
+package cmd_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gic/cmd"
+	"gic/internal/llm"
+)
+
+func TestExitCode(t *testing.T) {
+	failure := errors.New("failure")
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "success", err: nil, want: 0},
+		{name: "unmarked error", err: failure, want: cmd.ExitGeneric},
+		{name: "marked error", err: cmd.WithExitCode(cmd.ExitGit, failure), want: cmd.ExitGit},
+		{name: "marking nil", err: cmd.WithExitCode(cmd.ExitGit, nil), want: 0},
+		{
+			name: "wrapped marked error",
+			err:  fmt.Errorf("loading: %w", cmd.WithExitCode(cmd.ExitConfig, failure)),
+			want: cmd.ExitConfig,
+		},
+		{name: "no changes", err: llm.ErrNoChanges, want: cmd.ExitNoChanges},
+		{
+			name: "no changes wins over the mark",
+			err:  cmd.WithExitCode(cmd.ExitLLM, llm.ErrNoChanges),
+			want: cmd.ExitNoChanges,
+		},
+		{
+			name: "context too large wins over the mark",
+			err:  cmd.WithExitCode(cmd.ExitLLM, fmt.Errorf("summarising: %w", llm.ErrContextTooLarge)),
+			want: cmd.ExitContextTooLarge,
+		},
+		{
+			name: "content filtered wins over the mark",
+			err:  cmd.WithExitCode(cmd.ExitLLM, llm.ErrContentFiltered),
+			want: cmd.ExitContentFiltered,
+		},
+		{name: "lint", err: cmd.WithExitCode(cmd.ExitLint, failure), want: cmd.ExitLint},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cmd.ExitCode(tt.err); got != tt.want {
+				t.Errorf("expected exit code %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHandleError(t *testing.T) {
+	var out bytes.Buffer
+	code := cmd.HandleError(&out, cmd.WithExitCode(cmd.ExitGit, errors.New("not a git repository")))
+	if code != cmd.ExitGit {
+		t.Errorf("expected exit code %d, got %d", cmd.ExitGit, code)
+	}
+	if !strings.HasPrefix(out.String(), "gic: not a git repository\n") || !strings.Contains(out.String(), "--verbose") {
+		t.Errorf("expected the error and the --verbose hint, got %q", out.String())
+	}
+
+	out.Reset()
+	if code := cmd.HandleError(&out, llm.ErrNoChanges); code != cmd.ExitNoChanges {
+		t.Errorf("expected exit code %d, got %d", cmd.ExitNoChanges, code)
+	}
+	if out.String() != "gic: no changes to describe\n" {
+		t.Errorf("expected no --verbose hint when there are no changes, got %q", out.String())
+	}
+}
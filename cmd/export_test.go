@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+
+	"gic/internal/llm"
+)
+
+// WithExitCode exposes withExitCode to the tests.
+var WithExitCode = withExitCode
+
+// Review reviews the message with the answers read from input, and also returns what the review printed.
+func Review(
+	input, message string,
+	regenerate func(guidance string) (string, error),
+	lint func(message string) []string,
+) (string, bool, string, error) {
+	var out bytes.Buffer
+	r := &reviewer{in: bufio.NewReader(strings.NewReader(input)), out: &out, regenerate: regenerate, lint: lint}
+	approved, ok, err := r.review(message)
+	return approved, ok, out.String(), err
+}
+
+// PrintJSON prints the result as with --output json.
+func PrintJSON(w io.Writer, result llm.Result, message string, committed bool) error {
+	previous := outputFormat
+	outputFormat = outputJSON
+	defer func() { outputFormat = previous }()
+	return printResult(w, result, message, committed)
+}
+
+// RunHook runs gic hook run with args.
+func RunHook(args ...string) error {
+	return runHook(hookRunCmd, args)
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gic/internal/config"
+	"gic/internal/git"
+	"gic/internal/llm"
+	"gic/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+const hookRunMinArgs = 1
+
+var (
+	hookCmd = &cobra.Command{
+		Use:   "hook",
+		Short: "manage the gic prepare-commit-msg git hook",
+		Long: "Install or uninstall a prepare-commit-msg hook that fills the commit message " +
+			"with a generated one when running plain `git commit`.",
+	}
+	hookInstallCmd = &cobra.Command{
+		Use:   "install",
+		Short: "install the prepare-commit-msg hook in the current repository",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			path, err := git.InstallHook()
+			if err != nil {
+				return withExitCode(ExitGit, err)
+			}
+			logger.GetLogger().Info("Installed hook " + path)
+			return nil
+		},
+	}
+	hookUninstallCmd = &cobra.Command{
+		Use:   "uninstall",
+		Short: "remove the prepare-commit-msg hook from the current repository",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			path, err := git.UninstallHook()
+			if err != nil {
+				return withExitCode(ExitGit, err)
+			}
+			logger.GetLogger().Info("Removed hook " + path)
+			return nil
+		},
+	}
+	hookRunCmd = &cobra.Command{
+		Use:    "run <commit-msg-file> [source] [sha]",
+		Short:  "fill the commit message file, called by the prepare-commit-msg hook",
+		Hidden: true,
+		Args:   cobra.RangeArgs(hookRunMinArgs, 3),
+		RunE:   runHook,
+	}
+)
+
+// runHook generates a message for the staged changes and writes it at the top of the commit message file,
+// keeping the template and comments git already put there.
+func runHook(_ *cobra.Command, args []string) error {
+	l := logger.GetLogger()
+	file := args[0]
+	if len(args) > hookRunMinArgs && git.SkipsHook(args[1]) {
+		l.Debug("Skipping hook for commit source " + args[1])
+		return nil
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return withExitCode(ExitConfig, err)
+	}
+	cfg.PR = false
+
+	gitDiff, err := git.GetGitDiff(cfg)
+	if err != nil {
+		return withExitCode(ExitGit, err)
+	}
+	result, err := llm.GenerateCommitMessage(cfg, gitDiff)
+	if errors.Is(err, llm.ErrNoChanges) {
+		// Nothing is staged, let git handle the empty commit
+		return nil
+	}
+	if err != nil {
+		return withExitCode(ExitLLM, err)
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	existing, err := os.ReadFile(file) // #nosec G304 -- the file is given by git
+	if err != nil {
+		return err
+	}
+	content := strings.TrimSpace(result.Message) + "\n" + string(existing)
+	if err := os.WriteFile(file, []byte(content), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("writing commit message file: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	hookCmd.AddCommand(hookInstallCmd, hookUninstallCmd, hookRunCmd)
+	rootCmd.AddCommand(hookCmd)
+}
@@ -0,0 +1,124 @@
+package cmd_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"gic/cmd"
+	"gic/internal/logger"
+)
+
+const hookMessage = "feat(hook): fill the commit message"
+
+func TestMain(m *testing.M) {
+	logger.InitLogger()
+	os.Exit(m.Run())
+}
+
+// newHookRepo creates a repository with a staged file and a .gic.yaml in a temporary directory, makes it the
+// working directory and points the ollama provider to a server answering hookMessage. It returns the path of
+// a commit message file holding the template git writes, and the number of requests the server received.
+func newHookRepo(t *testing.T) (string, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"model":"phi3","message":{"role":"assistant","content":"` + hookMessage + `"},"done":true}`))
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("SERVICE_PROVIDER", "ollama")
+	t.Setenv("OLLAMA_API_KEY", "secret")
+	t.Setenv("OLLAMA_API_BASE", server.URL)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Error(err)
+		}
+	})
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.email", "gic@example.com"},
+		{"config", "user.name", "gic"},
+	} {
+		runGit(t, args...)
+	}
+	writeFile(t, ".gic.yaml", "should_commit: false\n")
+	writeFile(t, "main.go", "package main\n")
+	runGit(t, "add", "main.go")
+
+	file := ".git/COMMIT_EDITMSG"
+	writeFile(t, file, "\n# Please enter the commit message for your changes.\n")
+	return file, &requests
+}
+
+func runGit(t *testing.T, args ...string) {
+	t.Helper()
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+	content, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestRunHookWritesTheMessage(t *testing.T) {
+	for _, args := range [][]string{nil, {"template"}} {
+		t.Run(strings.Join(append([]string{"source"}, args...), " "), func(t *testing.T) {
+			file, _ := newHookRepo(t)
+			if err := cmd.RunHook(append([]string{file}, args...)...); err != nil {
+				t.Fatal(err)
+			}
+			got := readFile(t, file)
+			if !strings.HasPrefix(got, hookMessage+"\n") {
+				t.Errorf("expected the message at the top of the file, got %q", got)
+			}
+			if !strings.Contains(got, "# Please enter the commit message") {
+				t.Errorf("expected the template to be kept, got %q", got)
+			}
+		})
+	}
+}
+
+func TestRunHookSkipsSources(t *testing.T) {
+	for _, source := range []string{"message", "merge", "squash", "commit"} {
+		t.Run(source, func(t *testing.T) {
+			file, requests := newHookRepo(t)
+			before := readFile(t, file)
+			if err := cmd.RunHook(file, source, "HEAD"); err != nil {
+				t.Fatal(err)
+			}
+			if got := readFile(t, file); got != before {
+				t.Errorf("expected the file to be left alone, got %q", got)
+			}
+			if got := requests.Load(); got != 0 {
+				t.Errorf("expected no request to the provider, got %d", got)
+			}
+		})
+	}
+}
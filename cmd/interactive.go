@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"gic/internal/config"
+	"gic/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultEditor  = "vi"
+	commentPrefix  = "#"
+	editorTemplate = `
+
+# Edit the commit message above. Lines starting with '#' are ignored,
+# and an empty message aborts the commit.
+`
+)
+
+// reviewer lets the user accept, edit, regenerate or abort a generated commit message before it is committed.
+type reviewer struct {
+	in         *bufio.Reader
+	out        io.Writer
+	regenerate func(guidance string) (string, error)
+	// lint returns the problems of the message. A message with problems cannot be accepted.
+	lint func(message string) []string
+}
+
+// addInteractiveFlag registers --interactive on the commands that let the user review the message.
+func addInteractiveFlag(c *cobra.Command) {
+	c.Flags().BoolVarP(
+		&interactive,
+		"interactive",
+		"i",
+		false,
+		"review the message before committing: accept, edit, regenerate or abort",
+	)
+}
+
+// canReview reports whether the user can be asked to review the message, which needs a terminal on stdin.
+func canReview(cfg config.Config) bool {
+	return cfg.Interactive && !cfg.PR && isTerminal(os.Stdin)
+}
+
+// review loops until the user accepts or aborts the message. It returns the approved message,
+// or false when the user aborted.
+func (r *reviewer) review(message string) (string, bool, error) {
+	for {
+		fmt.Fprintf(r.out, "\n%s\n\n", message)
+		problems := r.problems(message)
+		r.printProblems(problems)
+		answer, err := r.ask("[a]ccept, [e]dit, [r]egenerate or a[b]ort? ")
+		if err != nil {
+			return emptyString, false, err
+		}
+		switch strings.ToLower(answer) {
+		case "a", "accept", "y", "yes":
+			if len(problems) == 0 {
+				return message, true, nil
+			}
+			fmt.Fprintln(r.out, "Edit or regenerate the message, or use --no-lint to commit it as is.")
+		case "e", "edit":
+			message, err = r.edit(message)
+		case "r", "regenerate":
+			message, err = r.regenerateMessage()
+		case "b", "abort", "q", "quit", "n", "no":
+			return emptyString, false, nil
+		default:
+			fmt.Fprintf(r.out, "Unknown choice %q.\n", answer)
+		}
+		// An empty message aborts the commit
+		if err != nil || message == emptyString {
+			return emptyString, false, err
+		}
+	}
+}
+
+// edit opens the message in the editor. It returns an empty message when the user emptied it.
+func (r *reviewer) edit(message string) (string, error) {
+	edited, err := editMessage(message)
+	if err != nil {
+		return emptyString, err
+	}
+	if edited == emptyString {
+		fmt.Fprintln(r.out, "Empty message, aborting commit.")
+	}
+	return edited, nil
+}
+
+// regenerateMessage asks the user for optional guidance and generates a new message with it.
+func (r *reviewer) regenerateMessage() (string, error) {
+	guidance, err := r.ask("Extra guidance for the new message (optional): ")
+	if err != nil {
+		return emptyString, err
+	}
+	return r.regenerate(guidance)
+}
+
+// printProblems shows the lint problems of the message under review.
+func (r *reviewer) printProblems(problems []string) {
+	if len(problems) > 0 {
+		fmt.Fprintf(r.out, "The message does not follow the commit rules:\n  - %s\n\n",
+			strings.Join(problems, "\n  - "))
+	}
+}
+
+func (r *reviewer) problems(message string) []string {
+	if r.lint == nil {
+		return nil
+	}
+	return r.lint(message)
+}
+
+// ask prints the prompt and returns the trimmed line typed by the user.
+func (r *reviewer) ask(prompt string) (string, error) {
+	fmt.Fprint(r.out, prompt)
+	line, err := r.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == emptyString) {
+		return emptyString, err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// editMessage opens the message in $VISUAL or $EDITOR and returns the edited text without comment lines.
+func editMessage(message string) (string, error) {
+	l := logger.GetLogger()
+	file, err := os.CreateTemp(emptyString, "gic-commit-*.txt")
+	if err != nil {
+		return emptyString, err
+	}
+	defer func() {
+		if rerr := os.Remove(file.Name()); rerr != nil {
+			l.Warn("Unable to remove temporary commit message file", "error", rerr)
+		}
+	}()
+	if _, err := file.WriteString(message + editorTemplate); err != nil {
+		if cerr := file.Close(); cerr != nil {
+			l.Warn("Unable to close temporary commit message file", "error", cerr)
+		}
+		return emptyString, err
+	}
+	if err := file.Close(); err != nil {
+		return emptyString, err
+	}
+
+	editor := strings.Fields(editorCommand())
+	cmd := exec.Command(editor[0], append(editor[1:], file.Name())...) // #nosec G204 -- the editor is chosen by the user
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return emptyString, fmt.Errorf("running editor %q: %w", strings.Join(editor, " "), err)
+	}
+
+	content, err := os.ReadFile(file.Name())
+	if err != nil {
+		return emptyString, err
+	}
+	return stripComments(string(content)), nil
+}
+
+func editorCommand() string {
+	for _, key := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(key)); editor != emptyString {
+			return editor
+		}
+	}
+	return defaultEditor
+}
+
+// stripComments removes the lines starting with '#' the same way git does for commit messages.
+func stripComments(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if !strings.HasPrefix(line, commentPrefix) {
+			lines = append(lines, strings.TrimRight(line, " \t\r"))
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
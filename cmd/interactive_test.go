@@ -0,0 +1,86 @@
+package cmd_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gic/cmd"
+)
+
+const generated = "feat: add the parser"
+
+func noRegenerate(string) (string, error) {
+	return "", errors.New("unexpected regeneration")
+}
+
+func TestReviewAccept(t *testing.T) {
+	approved, ok, out, err := cmd.Review("a\n", generated, noRegenerate, nil)
+	if err != nil || !ok || approved != generated {
+		t.Fatalf("expected %q to be accepted, got %q, %v, %v", generated, approved, ok, err)
+	}
+	if !strings.Contains(out, generated) {
+		t.Errorf("expected the message to be shown, got %q", out)
+	}
+}
+
+func TestReviewAbort(t *testing.T) {
+	approved, ok, _, err := cmd.Review("b\n", generated, noRegenerate, nil)
+	if err != nil || ok || approved != "" {
+		t.Fatalf("expected the review to be aborted, got %q, %v, %v", approved, ok, err)
+	}
+}
+
+func TestReviewEditToEmptyMessageAborts(t *testing.T) {
+	t.Setenv("VISUAL", "truncate -s 0")
+	approved, ok, out, err := cmd.Review("e\n", generated, noRegenerate, nil)
+	if err != nil || ok || approved != "" {
+		t.Fatalf("expected an empty message to abort, got %q, %v, %v", approved, ok, err)
+	}
+	if !strings.Contains(out, "Empty message") {
+		t.Errorf("expected the abort to be explained, got %q", out)
+	}
+}
+
+func TestReviewRegenerate(t *testing.T) {
+	var guidance string
+	regenerate := func(g string) (string, error) {
+		guidance = g
+		return "feat: add the parser and its tests", nil
+	}
+	approved, ok, _, err := cmd.Review("r\nmention the tests\na\n", generated, regenerate, nil)
+	if err != nil || !ok {
+		t.Fatalf("expected the regenerated message to be accepted, got %v, %v", ok, err)
+	}
+	if approved != "feat: add the parser and its tests" {
+		t.Errorf("unexpected message %q", approved)
+	}
+	if guidance != "mention the tests" {
+		t.Errorf("expected the guidance to be passed on, got %q", guidance)
+	}
+}
+
+func TestReviewEOF(t *testing.T) {
+	_, ok, _, err := cmd.Review("", generated, noRegenerate, nil)
+	if !errors.Is(err, io.EOF) || ok {
+		t.Fatalf("expected io.EOF, got %v, %v", ok, err)
+	}
+}
+
+func TestReviewAcceptBlockedByLint(t *testing.T) {
+	lint := func(message string) []string {
+		if message == "Added the parser" {
+			return []string{"the description should start in the imperative mood"}
+		}
+		return nil
+	}
+	regenerate := func(string) (string, error) { return generated, nil }
+	approved, ok, out, err := cmd.Review("a\nr\n\na\n", "Added the parser", regenerate, lint)
+	if err != nil || !ok || approved != generated {
+		t.Fatalf("expected only the regenerated message to be accepted, got %q, %v, %v", approved, ok, err)
+	}
+	if !strings.Contains(out, "imperative mood") || !strings.Contains(out, "--no-lint") {
+		t.Errorf("expected the problems and how to get past them, got %q", out)
+	}
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"gic/internal/config"
+	"gic/internal/conventional"
+
+	"github.com/spf13/cobra"
+)
+
+// addLintFlag registers --no-lint on the commands that commit generated messages.
+func addLintFlag(c *cobra.Command) {
+	c.Flags().BoolVar(
+		&noLint,
+		"no-lint",
+		false,
+		"commit the message even when it does not follow the Conventional Commits rules of the lint section",
+	)
+}
+
+// lintRules returns the Conventional Commits rules of the lint section of the config.
+func lintRules(cfg config.Config) conventional.Rules {
+	return conventional.Rules{
+		Types:            cfg.Lint.Types,
+		Scopes:           cfg.Lint.Scopes,
+		MaxSubjectLength: cfg.Lint.MaxSubjectLength,
+		Imperative:       cfg.Lint.Imperative,
+	}
+}
+
+// linting reports whether messages are linted before gic commits them.
+func linting(cfg config.Config) bool {
+	return cfg.Lint.Enabled && !noLint
+}
+
+// lintProblems returns the problems of a message, or none when linting is disabled.
+func lintProblems(cfg config.Config, message string) []string {
+	if !linting(cfg) {
+		return nil
+	}
+	return conventional.Lint(message, lintRules(cfg))
+}
+
+// validateMessage checks a message before gic commits it, so prose or code fences returned by the model
+// never end up in the history.
+func validateMessage(cfg config.Config, message string) error {
+	if !linting(cfg) {
+		return nil
+	}
+	return withExitCode(ExitLint, conventional.Validate(message, lintRules(cfg)))
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"gic/internal/conventional"
+	"gic/internal/llm"
+
+	"github.com/spf13/cobra"
+)
+
+// Values of --output.
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// messageOutput is the JSON object printed with --output json.
+type messageOutput struct {
+	Subject string `json:"subject"`
+	// Type and Scopes are only set when the subject follows the Conventional Commits format.
+	Type         string      `json:"type,omitempty"`
+	Scopes       []string    `json:"scopes,omitempty"`
+	Breaking     bool        `json:"breaking,omitempty"`
+	Body         string      `json:"body"`
+	Footers      []footer    `json:"footers"`
+	Provider     string      `json:"provider"`
+	Model        string      `json:"model"`
+	Usage        usageOutput `json:"usage"`
+	FinishReason string      `json:"finish_reason"`
+	ElapsedMS    int64       `json:"elapsed_ms"`
+	Committed    bool        `json:"committed"`
+}
+
+type footer struct {
+	Token string `json:"token"`
+	Value string `json:"value"`
+}
+
+type usageOutput struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// addOutputFlags registers --output and --output-file on the commands that print generated output.
+func addOutputFlags(c *cobra.Command) {
+	c.Flags().StringVar(
+		&outputFormat,
+		"output",
+		outputText,
+		"format of the generated output on stdout: text, or json with the message split into subject, body "+
+			"and footers, the provider, model, token usage and whether a commit was made",
+	)
+	c.Flags().StringVarP(
+		&outputFile,
+		"output-file",
+		"o",
+		emptyString,
+		"write the generated output to a file instead of stdout when nothing is committed",
+	)
+}
+
+// validateOutput checks the --output flag.
+func validateOutput(output string) error {
+	if output != outputText && output != outputJSON {
+		return fmt.Errorf("output must be %s or %s. got: %s", outputText, outputJSON, output)
+	}
+	return nil
+}
+
+// renderResult returns the message as text, or the JSON object describing it with --output json.
+// The message can differ from the one in the result when the user edited it.
+func renderResult(result llm.Result, message string, committed bool) (string, error) {
+	if outputFormat != outputJSON {
+		return message, nil
+	}
+	// A message that is not in the Conventional Commits format still has a subject, body and footers
+	parsed, err := conventional.Parse(message)
+	if err != nil && !errors.Is(err, conventional.ErrInvalidHeader) {
+		return emptyString, err
+	}
+	footers := make([]footer, 0, len(parsed.Footers))
+	for _, f := range parsed.Footers {
+		footers = append(footers, footer{Token: f.Token, Value: f.Value})
+	}
+	out, err := json.Marshal(messageOutput{
+		Subject:      parsed.Header,
+		Type:         parsed.Type,
+		Scopes:       parsed.Scopes,
+		Breaking:     parsed.Breaking,
+		Body:         parsed.Body,
+		Footers:      footers,
+		Provider:     result.Provider,
+		Model:        result.Model,
+		Usage:        usageOutput{InputTokens: result.Usage.InputTokens, OutputTokens: result.Usage.OutputTokens},
+		FinishReason: result.FinishReason,
+		ElapsedMS:    result.Elapsed.Milliseconds(),
+		Committed:    committed,
+	})
+	return string(out), err
+}
+
+// printResult writes the rendered result to w.
+func printResult(w io.Writer, result llm.Result, message string, committed bool) error {
+	out, err := renderResult(result, message, committed)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, out)
+	return err
+}
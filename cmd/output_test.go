@@ -0,0 +1,106 @@
+package cmd_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"gic/cmd"
+	"gic/internal/llm"
+)
+
+// jsonOutput mirrors the JSON object documented in the README.
+type jsonOutput struct {
+	Subject  string   `json:"subject"`
+	Type     string   `json:"type"`
+	Scopes   []string `json:"scopes"`
+	Breaking bool     `json:"breaking"`
+	Body     string   `json:"body"`
+	Footers  []struct {
+		Token string `json:"token"`
+		Value string `json:"value"`
+	} `json:"footers"`
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	Usage    struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	FinishReason string `json:"finish_reason"`
+	ElapsedMS    int64  `json:"elapsed_ms"`
+	Committed    bool   `json:"committed"`
+}
+
+var testResult = llm.Result{
+	Provider:     "ollama",
+	Model:        "llama3",
+	Usage:        llm.Usage{InputTokens: 120, OutputTokens: 30},
+	FinishReason: llm.FinishStop,
+	Elapsed:      1500 * time.Millisecond,
+}
+
+// printJSON prints the message as JSON and decodes it, along with the raw object.
+func printJSON(t *testing.T, message string, committed bool) (jsonOutput, map[string]json.RawMessage) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := cmd.PrintJSON(&buf, testResult, message, committed); err != nil {
+		t.Fatal(err)
+	}
+	var out jsonOutput
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &raw); err != nil {
+		t.Fatal(err)
+	}
+	return out, raw
+}
+
+func TestPrintJSONConventionalMessage(t *testing.T) {
+	message := "feat(parser,cli)!: add the parser\n\nParse the headers.\n\nBREAKING CHANGE: the API changed\nRefs: #3"
+	out, _ := printJSON(t, message, true)
+
+	if out.Subject != "feat(parser,cli)!: add the parser" || out.Type != "feat" || !out.Breaking {
+		t.Errorf("unexpected header fields %+v", out)
+	}
+	if !reflect.DeepEqual(out.Scopes, []string{"parser", "cli"}) {
+		t.Errorf("unexpected scopes %q", out.Scopes)
+	}
+	if out.Body != "Parse the headers." {
+		t.Errorf("unexpected body %q", out.Body)
+	}
+	if len(out.Footers) != 2 || out.Footers[0].Token != "BREAKING CHANGE" || out.Footers[1].Value != "#3" {
+		t.Errorf("unexpected footers %+v", out.Footers)
+	}
+	if out.Provider != "ollama" || out.Model != "llama3" || out.FinishReason != llm.FinishStop {
+		t.Errorf("unexpected provider fields %+v", out)
+	}
+	if out.Usage.InputTokens != 120 || out.Usage.OutputTokens != 30 || out.ElapsedMS != 1500 {
+		t.Errorf("unexpected usage %+v, elapsed %d", out.Usage, out.ElapsedMS)
+	}
+	if !out.Committed {
+		t.Error("expected committed to be true")
+	}
+}
+
+func TestPrintJSONNonConventionalMessage(t *testing.T) {
+	out, raw := printJSON(t, "Update the parser\n\nIt handles quoted paths now.", false)
+
+	if out.Subject != "Update the parser" || out.Body != "It handles quoted paths now." {
+		t.Errorf("unexpected subject and body %+v", out)
+	}
+	for _, key := range []string{"type", "scopes", "breaking"} {
+		if _, ok := raw[key]; ok {
+			t.Errorf("expected %s to be omitted, got %s", key, raw[key])
+		}
+	}
+	if got := string(raw["footers"]); got != "[]" {
+		t.Errorf("expected footers to default to [], got %s", got)
+	}
+	if out.Committed {
+		t.Error("expected committed to be false")
+	}
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gic/internal/config"
+	"gic/internal/git"
+	"gic/internal/llm"
+	"gic/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	rewordDryRun bool
+	rewordYes    bool
+	rewordForce  bool
+	rewordCmd    = &cobra.Command{
+		Use:   "reword <range>",
+		Short: "regenerate the messages of the commits in a range and rewrite them",
+		Long: "Generate a new message for every commit in the range from its own diff, show the plan, " +
+			"and rewrite the commits with the new messages. The range must end at HEAD, as in HEAD~5.. or main..HEAD; " +
+			"a single revision means <revision>..HEAD. Commits that were pushed or are on a protected branch " +
+			"are only rewritten with --force.",
+		Args: cobra.ExactArgs(1),
+		RunE: runReword,
+	}
+)
+
+func runReword(_ *cobra.Command, args []string) error {
+	l := logger.GetLogger()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return withExitCode(ExitConfig, err)
+	}
+	cfg.PR = false
+
+	commits, err := git.GetReword(args[0])
+	if err != nil {
+		return withExitCode(ExitGit, err)
+	}
+
+	messages, generated, err := rewordMessages(cfg, commits)
+	if err != nil {
+		return err
+	}
+	printRewordPlan(os.Stdout, commits, messages)
+	if rewordDryRun {
+		return nil
+	}
+	// A dry run rewrites nothing, so the messages are linted and the commits checked only now
+	if err := checkReword(cfg, commits, messages, generated); err != nil {
+		return err
+	}
+	if !rewordYes {
+		confirmed, err := confirmReword(len(commits))
+		if err != nil || !confirmed {
+			return err
+		}
+	}
+
+	head, err := git.Reword(commits, messages)
+	if err != nil {
+		return withExitCode(ExitGit, err)
+	}
+	l.Info("Reworded commits", "count", len(commits), "head", head)
+	return nil
+}
+
+// rewordMessages generates a new message for every commit. It also returns the indexes of the generated
+// messages, since empty commits keep their message.
+func rewordMessages(cfg config.Config, commits []git.RewordCommit) ([]string, []int, error) {
+	messages := make([]string, 0, len(commits))
+	var generated []int
+	for i, commit := range commits {
+		logger.GetLogger().Debug("Generating message for " + commit.Hash)
+		result, err := llm.GenerateCommitMessage(cfg, commit.Diff)
+		switch {
+		case errors.Is(err, llm.ErrNoChanges):
+			// Empty commits keep their message, which is not linted
+			result.Message = commit.Message
+		case err != nil:
+			return nil, nil, withExitCode(ExitLLM,
+				fmt.Errorf("generating the message of %s: %w", commit.Hash[:git.ShortHashLength], err))
+		default:
+			generated = append(generated, i)
+		}
+		messages = append(messages, strings.TrimSpace(result.Message))
+	}
+	return messages, generated, nil
+}
+
+// checkReword lints the generated messages, and refuses to rewrite pushed commits or protected branches
+// without --force.
+func checkReword(cfg config.Config, commits []git.RewordCommit, messages []string, generated []int) error {
+	for _, i := range generated {
+		if err := validateMessage(cfg, messages[i]); err != nil {
+			return fmt.Errorf("the message of %s: %w", commits[i].Hash[:git.ShortHashLength], err)
+		}
+	}
+	if rewordForce {
+		return nil
+	}
+	return withExitCode(ExitGit, git.CheckRewritable(commits, cfg.ProtectedBranches))
+}
+
+// confirmReword asks the user whether to rewrite the commits. It needs a terminal on stdin.
+func confirmReword(count int) (bool, error) {
+	if !isTerminal(os.Stdin) {
+		return false, fmt.Errorf("refusing to rewrite history without confirmation. Use --yes or --dry-run")
+	}
+	r := &reviewer{in: bufio.NewReader(os.Stdin), out: os.Stderr}
+	answer, err := r.ask(fmt.Sprintf("Rewrite %d commits? [y/N] ", count))
+	if err != nil {
+		return false, err
+	}
+	if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+		logger.GetLogger().Info("Reword aborted")
+		return false, nil
+	}
+	return true, nil
+}
+
+// printRewordPlan shows every commit with its current subject and the subject it is reworded to.
+func printRewordPlan(w io.Writer, commits []git.RewordCommit, messages []string) {
+	for i, commit := range commits {
+		subject, _, _ := strings.Cut(messages[i], "\n")
+		fmt.Fprintf(w, "%s %s\n", commit.Hash[:git.ShortHashLength], commit.Subject())
+		fmt.Fprintf(w, "%*s -> %s\n", git.ShortHashLength, emptyString, subject)
+	}
+}
+
+func init() {
+	rewordCmd.Flags().BoolVar(&rewordDryRun, "dry-run", false, "only show the new messages")
+	rewordCmd.Flags().BoolVarP(&rewordYes, "yes", "y", false, "rewrite without asking for confirmation")
+	rewordCmd.Flags().BoolVar(&rewordForce, "force", false, "rewrite pushed commits and protected branches")
+	addLintFlag(rewordCmd)
+	rootCmd.AddCommand(rewordCmd)
+}
@@ -2,7 +2,9 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"os"
 
 	"gic/internal/config"
 	"gic/internal/git"
@@ -12,29 +14,49 @@ import (
 	"github.com/spf13/cobra"
 )
 
+const emptyString = ""
+
 var (
 	hash               string
 	verbose            bool
 	createSampleConfig bool
 	createSampleDotEnv bool
 	pullRequest        bool
+	stream             bool
+	interactive        bool
+	baseBranch         string
+	noFetch            bool
+	behindPolicy       string
+	outputFile         string
+	revRange           string
+	amend              bool
+	all                bool
+	includeUntracked   bool
+	logFormat          string
+	logFile            string
+	outputFormat       string
+	noLint             bool
 	rootCmd            = &cobra.Command{
 		Use:   "gic",
 		Short: "gic",
 		Long:  "gic generates git commit messages based on staged changes.",
-		PersistentPreRunE: func(_ *cobra.Command, args []string) error {
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
 			// Set logger level based on the verbose flag
 			if verbose {
 				logger.SetLogLevel("debug")
 			} else {
 				logger.SetLogLevel("info")
 			}
-
-			// Check for non-flag arguments
+			if err := validateOutput(outputFormat); err != nil {
+				return err
+			}
+			return configureLogOutput()
+		},
+		// Reject non-flag arguments, subcommands declare their own
+		Args: func(_ *cobra.Command, args []string) error {
 			if len(args) > 0 {
 				return fmt.Errorf("unexpected arguments: %v", args)
 			}
-
 			return nil
 		},
 	}
@@ -46,6 +68,12 @@ func Execute(version, commit string) error {
 	hash = commit
 	setVersion()
 	rootCmd.RunE = executeCmd
+	// Failures such as having nothing staged are not usage mistakes, and errors are printed by HandleError
+	rootCmd.SilenceUsage = true
+	rootCmd.SilenceErrors = true
+	rootCmd.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
+		return fmt.Errorf("%w. See '%s --help'", err, c.CommandPath())
+	})
 	return rootCmd.Execute()
 }
 
@@ -62,28 +90,212 @@ func executeCmd(_ *cobra.Command, _ []string) error {
 	l.Debug("Start loading configuration")
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		return err
+		return withExitCode(ExitConfig, err)
 	}
 	l.Debug("Finish loading configuration")
+	if err := applyFlags(&cfg); err != nil {
+		return withExitCode(ExitConfig, err)
+	}
 
+	switch {
+	case cfg.PR:
+		return generatePullRequest(cfg)
+	case revRange != emptyString:
+		return summarizeRange(cfg, revRange)
+	default:
+		return commitChanges(cfg)
+	}
+}
+
+// applyFlags copies the command-line flags into the configuration. Flags win over the config file.
+func applyFlags(cfg *config.Config) error {
 	// Include the pullRequest flag in the configuration
 	cfg.PR = pullRequest
+	cfg.Stream = cfg.Stream || stream
+	cfg.Interactive = cfg.Interactive || interactive
+	if baseBranch != emptyString {
+		cfg.BaseBranch = baseBranch
+	}
+	cfg.NoFetch = cfg.NoFetch || noFetch
+	// --amend asks for the commit to be rewritten, so it commits even without should_commit
+	cfg.Amend = amend
+	cfg.ShouldCommit = cfg.ShouldCommit || amend
+	cfg.All = all
+	cfg.IncludeUntracked = includeUntracked
+	if behindPolicy != emptyString {
+		if err := config.ValidateBehindPolicy(behindPolicy); err != nil {
+			return err
+		}
+		cfg.BehindPolicy = behindPolicy
+	}
+	return nil
+}
 
+// commitChanges generates a message for the changes and prints it, or writes it to the --output-file,
+// or commits it when should_commit is set or the user accepts it in the review.
+func commitChanges(cfg config.Config) error {
+	l := logger.GetLogger()
 	gitDiff, err := git.GetGitDiff(cfg)
 	if err != nil {
-		return err
+		return withExitCode(ExitGit, err)
 	}
 
 	l.Debug("Start generating commit message")
-	commitMessage, err := llm.GenerateCommitMessage(cfg, gitDiff)
+	result, err := generateCommitMessage(cfg, gitDiff)
+	if err != nil {
+		return withExitCode(ExitLLM, err)
+	}
+	l.Debug("Generated commit message", "provider", result.Provider, "model", result.Model,
+		"input_tokens", result.Usage.InputTokens, "output_tokens", result.Usage.OutputTokens,
+		"finish_reason", result.FinishReason)
+	if canReview(cfg) {
+		return reviewAndCommit(cfg, gitDiff, result)
+	}
+	if !cfg.ShouldCommit {
+		// A streamed message is already on stdout
+		if streams(cfg) && outputFile == emptyString {
+			return nil
+		}
+		return writeOutput(result, result.Message)
+	}
+	// The message is shown before it is committed, while the JSON output waits for the commit
+	if !streams(cfg) && outputFormat == outputText {
+		fmt.Fprintln(os.Stdout, result.Message)
+	}
+	return commit(cfg, result, result.Message)
+}
+
+// reviewAndCommit lets the user review the message, and commits the approved one.
+func reviewAndCommit(cfg config.Config, gitDiff string, result llm.Result) error {
+	r := &reviewer{
+		in:  bufio.NewReader(os.Stdin),
+		out: os.Stderr,
+		regenerate: func(guidance string) (string, error) {
+			cfg.Guidance = guidance
+			var err error
+			result, err = generateCommitMessage(cfg, gitDiff)
+			return result.Message, withExitCode(ExitLLM, err)
+		},
+		lint: func(message string) []string { return lintProblems(cfg, message) },
+	}
+	approved, ok, err := r.review(result.Message)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		logger.GetLogger().Info("Commit aborted")
+		return printJSONResult(result, result.Message, false)
+	}
+	cfg.ShouldCommit = true
+	return commit(cfg, result, approved)
+}
+
+// commit lints and commits the message when should_commit is set. git.Commit does nothing otherwise.
+func commit(cfg config.Config, result llm.Result, message string) error {
+	if cfg.ShouldCommit {
+		if err := validateMessage(cfg, message); err != nil {
+			return err
+		}
+	}
+	if err := git.Commit(message, cfg); err != nil {
+		return withExitCode(ExitGit, err)
+	}
+	return printJSONResult(result, message, cfg.ShouldCommit)
+}
+
+// printJSONResult prints the result on stdout with --output json. The text output is printed before committing.
+func printJSONResult(result llm.Result, message string, committed bool) error {
+	if outputFormat != outputJSON {
+		return nil
+	}
+	return printResult(os.Stdout, result, message, committed)
+}
+
+// generateCommitMessage streams the message to the terminal as it is generated when streaming is enabled
+// and stdout is a terminal. Otherwise it waits for the whole message.
+func generateCommitMessage(cfg config.Config, gitDiff string) (llm.Result, error) {
+	if !streams(cfg) {
+		return llm.GenerateCommitMessage(cfg, gitDiff)
+	}
+	result, err := llm.StreamCommitMessage(cfg, gitDiff, os.Stdout)
+	fmt.Fprintln(os.Stdout)
+	return result, err
+}
+
+// generatePullRequest writes a pull request title and description for the current branch to stdout,
+// or to the --output-file.
+func generatePullRequest(cfg config.Config) error {
+	l := logger.GetLogger()
+	l.Debug("Start getting the pull request changes")
+	pr, err := git.GetPullRequest(cfg)
 	if err != nil {
+		return withExitCode(ExitGit, err)
+	}
+
+	l.Debug("Start generating pull request description")
+	description, err := llm.GeneratePullRequest(cfg, pr.Commits, pr.DiffStat, pr.Diff)
+	if err != nil {
+		return withExitCode(ExitLLM, err)
+	}
+	return writeOutput(description, description.Message)
+}
+
+// summarizeRange writes a message describing the commits of a revision range to stdout, or to the --output-file.
+func summarizeRange(cfg config.Config, revRange string) error {
+	l := logger.GetLogger()
+	l.Debug("Start getting the changes of " + revRange)
+	changes, err := git.GetRange(revRange)
+	if err != nil {
+		return withExitCode(ExitGit, err)
+	}
+
+	l.Debug("Start summarising " + revRange)
+	summary, err := llm.SummarizeRange(cfg, changes.Commits, changes.DiffStat, changes.Diff)
+	if err != nil {
+		return withExitCode(ExitLLM, err)
+	}
+	return writeOutput(summary, summary.Message)
+}
+
+// writeOutput prints the generated text, or its JSON object with --output json, to stdout,
+// or writes it to the --output-file when it is set.
+func writeOutput(result llm.Result, message string) error {
+	if outputFile == emptyString {
+		return printResult(os.Stdout, result, message, false)
+	}
+	text, err := renderResult(result, message, false)
+	if err != nil {
+		return err
+	}
+	// #nosec G306 -- the description or summary is meant to be shared, like the files git writes
+	if err := os.WriteFile(outputFile, []byte(text+"\n"), 0o644); err != nil {
+		return err
+	}
+	logger.GetLogger().Info("Output written to " + outputFile)
+	return nil
+}
+
+// streams reports whether the message is streamed to stdout as it is generated.
+func streams(cfg config.Config) bool {
+	return cfg.Stream && outputFormat == outputText && isTerminal(os.Stdout)
+}
+
+// configureLogOutput applies --log-format and --log-file. Logs are written to stderr unless a file is given,
+// so stdout only carries the generated output.
+func configureLogOutput() error {
+	if err := logger.SetFormat(logFormat); err != nil {
 		return err
 	}
-	if commitMessage == "### NO STAGED CHAGES ###" {
+	if logFile == emptyString {
 		return nil
 	}
-	l.Info("commit message: " + commitMessage)
-	return git.Commit(commitMessage, cfg)
+	file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	// The file stays open until gic exits
+	logger.SetOutput(file)
+	return nil
 }
 
 // handleCreateSampleConfig creates a sample configuration file and logs the process.
@@ -116,6 +328,18 @@ func setVersion() {
 func init() {
 	cobra.OnInitialize()
 	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "set logging level to verbose")
+	rootCmd.PersistentFlags().StringVar(
+		&logFormat,
+		"log-format",
+		logger.FormatText,
+		"format of the logs written to stderr: text or json",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&logFile,
+		"log-file",
+		emptyString,
+		"append the logs to a file instead of writing them to stderr",
+	)
 	rootCmd.PersistentFlags().BoolVarP(
 		&createSampleDotEnv,
 		"create-sample-dotenv",
@@ -130,12 +354,65 @@ func init() {
 		false,
 		"create a sample configuration file in the running directory",
 	)
-	// create a flag for --pull-request or -p default false and is used to generate a message against source main
-	rootCmd.PersistentFlags().BoolVarP(
+	// The flags below only apply to gic itself, the subcommands register the shared ones they support
+	rootCmd.Flags().BoolVarP(
 		&pullRequest,
 		"pull-request",
 		"p",
 		false,
-		"generate a commit message comparing against main branch",
+		"generate a pull request title and description comparing against the base branch",
+	)
+	addOutputFlags(rootCmd)
+	addInteractiveFlag(rootCmd)
+	addLintFlag(rootCmd)
+	rootCmd.Flags().StringVar(
+		&revRange,
+		"range",
+		emptyString,
+		"summarise the commits of a revision range such as v1.2.0..HEAD instead of the staged changes",
+	)
+	rootCmd.MarkFlagsMutuallyExclusive("range", "pull-request")
+	rootCmd.Flags().StringVar(
+		&baseBranch,
+		"base",
+		emptyString,
+		"base branch for --pull-request, e.g. develop or upstream/master. Defaults to the remote's default branch",
+	)
+	rootCmd.Flags().BoolVar(
+		&noFetch,
+		"no-fetch",
+		false,
+		"do not run git fetch before comparing against the base branch",
+	)
+	rootCmd.Flags().StringVar(
+		&behindPolicy,
+		"behind",
+		emptyString,
+		"what to do when the local base branch is behind the remote one: warn, fail or merge-base",
+	)
+	rootCmd.Flags().BoolVarP(
+		&all,
+		"all",
+		"a",
+		false,
+		"describe every change to tracked files, staged or not, and stage them before committing",
+	)
+	rootCmd.Flags().BoolVar(
+		&includeUntracked,
+		"include-untracked",
+		false,
+		"like --all, and also describe and stage untracked files",
+	)
+	rootCmd.Flags().BoolVar(
+		&amend,
+		"amend",
+		false,
+		"regenerate the message of HEAD from its changes and the staged ones, and amend it",
+	)
+	rootCmd.Flags().BoolVar(
+		&stream,
+		"stream",
+		false,
+		"print the message as it is generated when stdout is a terminal",
 	)
 }
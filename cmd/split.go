@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gic/internal/config"
+	"gic/internal/git"
+	"gic/internal/llm"
+	"gic/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	splitDryRun bool
+	splitCmd    = &cobra.Command{
+		Use:   "split",
+		Short: "split the staged changes into several logical commits",
+		Long: "Ask the LLM to group the staged files into logical units, each with its own message, " +
+			"and commit them one by one. Use --dry-run to only show the proposed grouping.",
+		Args: cobra.NoArgs,
+		RunE: runSplit,
+	}
+)
+
+func runSplit(_ *cobra.Command, _ []string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return withExitCode(ExitConfig, err)
+	}
+	cfg.PR = false
+
+	files, err := git.GetStagedFiles()
+	if err != nil {
+		return withExitCode(ExitGit, err)
+	}
+	if len(files) == 0 {
+		return llm.ErrNoChanges
+	}
+	groups, err := planSplit(cfg, files)
+	if err != nil {
+		return err
+	}
+
+	printSplitPlan(os.Stdout, groups)
+	if splitDryRun {
+		return nil
+	}
+	return commitSplit(cfg, groups, files)
+}
+
+// planSplit asks the LLM to group the staged files into commits.
+func planSplit(cfg config.Config, files []git.StagedFile) ([]llm.SplitGroup, error) {
+	gitDiff, err := git.GetGitDiff(cfg)
+	if err != nil {
+		return nil, withExitCode(ExitGit, err)
+	}
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	groups, err := llm.SplitChanges(cfg, paths, gitDiff)
+	if err != nil {
+		return nil, withExitCode(ExitLLM, err)
+	}
+	return groups, nil
+}
+
+// commitSplit lints the message of every group and commits the groups one by one.
+func commitSplit(cfg config.Config, groups []llm.SplitGroup, files []git.StagedFile) error {
+	byPath := make(map[string]git.StagedFile, len(files))
+	for _, file := range files {
+		byPath[file.Path] = file
+	}
+	staged := make([][]git.StagedFile, 0, len(groups))
+	messages := make([]string, 0, len(groups))
+	for i, group := range groups {
+		if err := validateMessage(cfg, group.Message); err != nil {
+			return fmt.Errorf("commit %d: %w", i+1, err)
+		}
+		var groupFiles []git.StagedFile
+		for _, path := range group.Files {
+			groupFiles = append(groupFiles, byPath[path])
+		}
+		staged = append(staged, groupFiles)
+		messages = append(messages, group.Message)
+	}
+	if err := git.CommitGroups(staged, messages); err != nil {
+		return withExitCode(ExitGit, err)
+	}
+	logger.GetLogger().Info("Committed staged changes", "commits", len(groups))
+	return nil
+}
+
+// printSplitPlan shows the message and the files of every proposed commit.
+func printSplitPlan(w io.Writer, groups []llm.SplitGroup) {
+	for i, group := range groups {
+		subject, _, _ := strings.Cut(group.Message, "\n")
+		fmt.Fprintf(w, "%d. %s\n", i+1, subject)
+		for _, file := range group.Files {
+			fmt.Fprintf(w, "   %s\n", file)
+		}
+	}
+}
+
+func init() {
+	splitCmd.Flags().BoolVar(&splitDryRun, "dry-run", false, "only show the proposed commits")
+	addLintFlag(splitCmd)
+	rootCmd.AddCommand(splitCmd)
+}
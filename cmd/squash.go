@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bufio"
+	"os"
+
+	"gic/internal/config"
+	"gic/internal/git"
+	"gic/internal/llm"
+	"gic/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+var squashCmd = &cobra.Command{
+	Use:   "squash <base>",
+	Short: "generate one message for the commits since base and squash them",
+	Long: "Collect the messages and the combined diff of the commits between base and HEAD and generate " +
+		"one commit message for them. The message is printed, unless should_commit is set or it is accepted " +
+		"with --interactive, in which case the commits are squashed into one with git reset --soft and git commit.",
+	Args: cobra.ExactArgs(1),
+	RunE: runSquash,
+}
+
+func runSquash(_ *cobra.Command, args []string) error {
+	l := logger.GetLogger()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return withExitCode(ExitConfig, err)
+	}
+	cfg.PR = false
+	cfg.Interactive = cfg.Interactive || interactive
+
+	squash, err := git.GetSquash(args[0])
+	if err != nil {
+		return withExitCode(ExitGit, err)
+	}
+	l.Debug("Squashing commits", "count", len(squash.Messages), "base", squash.Base)
+
+	result, err := llm.GenerateSquashMessage(cfg, squash.Messages, squash.Diff)
+	if err != nil {
+		return withExitCode(ExitLLM, err)
+	}
+	if canReview(cfg) {
+		return reviewAndSquash(cfg, squash, result)
+	}
+	return squashCommits(cfg, squash, result, result.Message)
+}
+
+// reviewAndSquash lets the user review the message, and squashes the commits with the approved one.
+func reviewAndSquash(cfg config.Config, squash git.Squash, result llm.Result) error {
+	r := &reviewer{
+		in:  bufio.NewReader(os.Stdin),
+		out: os.Stderr,
+		regenerate: func(guidance string) (string, error) {
+			cfg.Guidance = guidance
+			var err error
+			result, err = llm.GenerateSquashMessage(cfg, squash.Messages, squash.Diff)
+			return result.Message, withExitCode(ExitLLM, err)
+		},
+		lint: func(message string) []string { return lintProblems(cfg, message) },
+	}
+	approved, ok, err := r.review(result.Message)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		logger.GetLogger().Info("Squash aborted")
+		return printJSONResult(result, result.Message, false)
+	}
+	cfg.ShouldCommit = true
+	return squashCommits(cfg, squash, result, approved)
+}
+
+// squashCommits squashes the commits with the message when should_commit is set, and prints it otherwise.
+func squashCommits(cfg config.Config, squash git.Squash, result llm.Result, message string) error {
+	if !cfg.ShouldCommit {
+		return writeOutput(result, message)
+	}
+	if err := validateMessage(cfg, message); err != nil {
+		return err
+	}
+	if err := git.SquashCommits(squash.Base, message); err != nil {
+		return withExitCode(ExitGit, err)
+	}
+	logger.GetLogger().Info("Squashed commits", "count", len(squash.Messages))
+	return printJSONResult(result, message, true)
+}
+
+func init() {
+	addOutputFlags(squashCmd)
+	addInteractiveFlag(squashCmd)
+	addLintFlag(squashCmd)
+	rootCmd.AddCommand(squashCmd)
+}
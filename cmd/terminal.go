@@ -0,0 +1,12 @@
+package cmd
+
+import "os"
+
+// isTerminal reports whether the file is connected to a terminal rather than a pipe or a regular file.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
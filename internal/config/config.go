@@ -3,8 +3,13 @@ package config
 
 import (
 	"fmt"
+	"gic/internal/conventional"
 	"gic/internal/logger"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
@@ -12,15 +17,101 @@ import (
 
 const emptyString = ""
 const defaultInstructions = "You are a helpful assistant, that helps generating commit messages based on git diffs."
+const defaultPRInstructions = `You are a helpful assistant that writes pull request descriptions. ` +
+	`The user provides the commit subjects, the diffstat and the diff of a branch. ` +
+	`Return ONLY Markdown in this format:
+
+# <short title in imperative mood>
+
+## Summary
+<one paragraph explaining what the pull request does and why>
+
+## Changes
+- <one bullet per notable change>
+
+## Testing
+- <how the changes were or should be tested>`
 const defaultOpenAIDeploymentName = "gpt-4o-mini"
 const defaultOllamaDeploymentName = "phi3"
+const defaultAnthropicBaseURL = "https://api.anthropic.com"
+const defaultAnthropicModel = "claude-3-5-haiku-latest"
+const defaultAnthropicMaxTokens = 1024
+const defaultGeminiAPIBase = "https://generativelanguage.googleapis.com/v1beta"
+const defaultGeminiModel = "gemini-1.5-flash"
+const defaultTimeout = 2 * time.Minute
+const defaultMaxRetries = 3
+const defaultRetryBackoff = time.Second
+const defaultRetryMaxBackoff = 30 * time.Second
+
+// defaultProtectedBranches are the branches history is not rewritten on unless protected_branches says otherwise.
+var defaultProtectedBranches = []string{"main", "master"}
+
+// Values of behind_policy, deciding what happens when the local base branch is behind the remote one.
+const (
+	BehindPolicyWarn      = "warn"
+	BehindPolicyFail      = "fail"
+	BehindPolicyMergeBase = "merge-base"
+)
 
 // Config represents the configuration for the application.
 type Config struct {
 	ConnectionConfig connectionConfig `mapstructure:"connection_config"`
 	LLMInstructions  string           `mapstructure:"llm_instructions"`
+	PRInstructions   string           `mapstructure:"pr_instructions"`
 	ShouldCommit     bool             `mapstructure:"should_commit"`
 	PR               bool             `mapstructure:"pr"`
+	MaxInputTokens   int              `mapstructure:"max_input_tokens"`
+	ChunkConcurrency int              `mapstructure:"chunk_concurrency"`
+	Stream           bool             `mapstructure:"stream"`
+	Timeout          time.Duration    `mapstructure:"timeout"`
+	MaxRetries       int              `mapstructure:"max_retries"`
+	RetryBackoff     time.Duration    `mapstructure:"retry_backoff"`
+	RetryMaxBackoff  time.Duration    `mapstructure:"retry_max_backoff"`
+	Interactive      bool             `mapstructure:"interactive"`
+	BaseBranch       string           `mapstructure:"base_branch"`
+	Remote           string           `mapstructure:"remote"`
+	NoFetch          bool             `mapstructure:"no_fetch"`
+	BehindPolicy     string           `mapstructure:"behind_policy"`
+	// ProtectedBranches are the branches gic reword refuses to rewrite without --force.
+	ProtectedBranches []string `mapstructure:"protected_branches"`
+	// Lint holds the rules generated messages are checked against before gic commits them.
+	Lint LintConfig `mapstructure:"lint"`
+	// All describes the changes of every tracked file, staged or not, like git commit -a. It is set with --all only.
+	All bool `mapstructure:"-"`
+	// IncludeUntracked also describes untracked files, and implies All. It is set with --include-untracked only.
+	IncludeUntracked bool `mapstructure:"-"`
+	// Amend regenerates the message of HEAD and amends it. It is set with --amend only.
+	Amend bool `mapstructure:"-"`
+	// Guidance is extra direction from the user for regenerating a message. It is never read from the config file.
+	Guidance string `mapstructure:"-"`
+}
+
+// LintConfig holds the Conventional Commits rules of the lint section.
+type LintConfig struct {
+	Enabled          bool     `mapstructure:"enabled"`
+	Types            []string `mapstructure:"types"`
+	Scopes           []string `mapstructure:"scopes"`
+	MaxSubjectLength int      `mapstructure:"max_subject_length"`
+	Imperative       bool     `mapstructure:"imperative"`
+}
+
+// ProviderValidator checks that the configuration has everything a service provider needs.
+type ProviderValidator func(cfg Config) error
+
+var providerValidators = map[string]ProviderValidator{}
+
+// RegisterProvider registers the validation for a SERVICE_PROVIDER value.
+func RegisterProvider(name string, validate ProviderValidator) {
+	providerValidators[name] = validate
+}
+
+func providerNames() []string {
+	names := make([]string, 0, len(providerValidators))
+	for name := range providerValidators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 type connectionConfig struct {
@@ -28,6 +119,9 @@ type connectionConfig struct {
 	OpenAIAPIKey              string
 	OpenAIAPIBase             string
 	OpenAIDeploymentName      string
+	OpenAIOrganization        string
+	OpenAIProject             string
+	OpenAIExtraHeaders        map[string]string
 	AzureAuthenticationType   string
 	AzureOpenAIAPIKey         string
 	AzureOpenAIEndpoint       string
@@ -35,6 +129,15 @@ type connectionConfig struct {
 	OllamaAPIKey              string
 	OllamaAPIBase             string
 	OllamaDeploymentName      string
+	OllamaExtraHeaders        map[string]string
+	OllamaCACert              string
+	AnthropicAPIKey           string
+	AnthropicBaseURL          string
+	AnthropicModel            string
+	AnthropicMaxTokens        int
+	GeminiAPIKey              string
+	GeminiAPIBase             string
+	GeminiModel               string
 }
 
 // LoadConfig loads the configuration from the configuration file and environment variables
@@ -46,6 +149,15 @@ func LoadConfig() (Config, error) {
 	viper.SetConfigName(".gic")
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath(".")
+	viper.SetDefault("timeout", defaultTimeout)
+	viper.SetDefault("max_retries", defaultMaxRetries)
+	viper.SetDefault("retry_backoff", defaultRetryBackoff)
+	viper.SetDefault("retry_max_backoff", defaultRetryMaxBackoff)
+	// Linting is opt-in, the default llm_instructions do not ask for Conventional Commits
+	viper.SetDefault("lint.enabled", false)
+	viper.SetDefault("lint.types", conventional.DefaultTypes)
+	viper.SetDefault("lint.max_subject_length", conventional.DefaultMaxSubjectLength)
+	viper.SetDefault("lint.imperative", true)
 
 	l.Debug("reading config from: " + os.Getenv("PWD") + "/.gic.yaml")
 	if err := viper.ReadInConfig(); err != nil {
@@ -59,6 +171,7 @@ func LoadConfig() (Config, error) {
 	l.Debug("config unmarshalled successfully")
 	l.Debug("loading connection config from environment variables")
 	cfg.ConnectionConfig = loadConnectionConfigFromEnv()
+	setDefaults(&cfg)
 	l.Debug("validating config")
 	if err := validateConfig(cfg); err != nil {
 		return cfg, err
@@ -81,11 +194,30 @@ func loadConnectionConfigFromEnv() connectionConfig {
 	if ollamaDeploymentName == emptyString {
 		ollamaDeploymentName = defaultOllamaDeploymentName
 	}
+	anthropicBaseURL := os.Getenv("ANTHROPIC_BASE_URL")
+	if anthropicBaseURL == emptyString {
+		anthropicBaseURL = defaultAnthropicBaseURL
+	}
+	anthropicModel := os.Getenv("ANTHROPIC_MODEL")
+	if anthropicModel == emptyString {
+		anthropicModel = defaultAnthropicModel
+	}
+	geminiAPIBase := os.Getenv("GEMINI_API_BASE")
+	if geminiAPIBase == emptyString {
+		geminiAPIBase = defaultGeminiAPIBase
+	}
+	geminiModel := os.Getenv("GEMINI_MODEL")
+	if geminiModel == emptyString {
+		geminiModel = defaultGeminiModel
+	}
 	return connectionConfig{
 		ServiceProvider:           os.Getenv("SERVICE_PROVIDER"),
 		OpenAIAPIKey:              os.Getenv("OPENAI_API_KEY"),
 		OpenAIAPIBase:             os.Getenv("OPENAI_API_BASE"),
 		OpenAIDeploymentName:      openAIDeploymentName,
+		OpenAIOrganization:        os.Getenv("OPENAI_ORG_ID"),
+		OpenAIProject:             os.Getenv("OPENAI_PROJECT_ID"),
+		OpenAIExtraHeaders:        parseHeaders(os.Getenv("OPENAI_EXTRA_HEADERS")),
 		AzureAuthenticationType:   os.Getenv("AZURE_AUTHENTICATION_TYPE"),
 		AzureOpenAIAPIKey:         os.Getenv("AZURE_OPENAI_API_KEY"),
 		AzureOpenAIEndpoint:       os.Getenv("AZURE_OPENAI_ENDPOINT"),
@@ -93,79 +225,104 @@ func loadConnectionConfigFromEnv() connectionConfig {
 		OllamaAPIKey:              os.Getenv("OLLAMA_API_KEY"),
 		OllamaAPIBase:             os.Getenv("OLLAMA_API_BASE"),
 		OllamaDeploymentName:      ollamaDeploymentName,
+		OllamaExtraHeaders:        parseHeaders(os.Getenv("OLLAMA_EXTRA_HEADERS")),
+		OllamaCACert:              os.Getenv("OLLAMA_CA_CERT"),
+		AnthropicAPIKey:           os.Getenv("ANTHROPIC_API_KEY"),
+		AnthropicBaseURL:          anthropicBaseURL,
+		AnthropicModel:            anthropicModel,
+		AnthropicMaxTokens:        intFromEnv("ANTHROPIC_MAX_TOKENS", defaultAnthropicMaxTokens),
+		GeminiAPIKey:              os.Getenv("GEMINI_API_KEY"),
+		GeminiAPIBase:             geminiAPIBase,
+		GeminiModel:               geminiModel,
 	}
 }
 
-func validateConfig(cfg Config) error {
-	l := logger.GetLogger()
-	l.Debug("Validating config")
-	if cfg.LLMInstructions == emptyString {
-		l.Debug("LLMInstructions not set in config. Using default value." + defaultInstructions)
-		cfg.LLMInstructions = defaultInstructions
+// intFromEnv reads a positive integer from the environment, falling back to the default value when
+// the variable is not set or is not a positive integer.
+func intFromEnv(key string, defaultValue int) int {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == emptyString {
+		return defaultValue
 	}
-
-	return validateConnectionConfig(cfg.ConnectionConfig)
-}
-
-func validateConnectionConfig(connCfg connectionConfig) error {
-	l := logger.GetLogger()
-	l.Debug("Validating connection config from environment")
-	switch connCfg.ServiceProvider {
-	case "openai":
-		return validateOpenAIConfig(connCfg)
-	case "azure":
-		return validateAzureConfig(connCfg)
-	case "ollama":
-		return validateOllamaConfig(connCfg)
-	default:
-		return fmt.Errorf(
-			"unsupported service provider. options are openai, azure or ollama. got: %s. "+
-				"Options are openai, azure or ollama",
-			connCfg.ServiceProvider,
-		)
+	value, err := strconv.Atoi(raw)
+	if err != nil || value <= 0 {
+		logger.GetLogger().Warn("Ignoring invalid "+key+". Using default value", "value", raw, "default", defaultValue)
+		return defaultValue
 	}
+	return value
 }
 
-func validateOpenAIConfig(connCfg connectionConfig) error {
-	if connCfg.OpenAIAPIKey == emptyString {
-		return fmt.Errorf("OPENAI_API_KEY environment variable not set")
-	}
-	if connCfg.OpenAIAPIBase == emptyString {
-		return fmt.Errorf("OPENAI_API_BASE environment variable not set")
+// parseHeaders parses a comma separated list of key=value pairs into a header map.
+// Malformed pairs are skipped with a warning.
+func parseHeaders(raw string) map[string]string {
+	l := logger.GetLogger()
+	headers := map[string]string{}
+	for _, pair := range strings.Split(raw, ",") {
+		if strings.TrimSpace(pair) == emptyString {
+			continue
+		}
+		key, value, found := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == emptyString {
+			l.Warn("Ignoring malformed header, expected key=value", "header", pair)
+			continue
+		}
+		headers[key] = strings.TrimSpace(value)
 	}
-	return nil
+	return headers
 }
 
-func validateAzureConfig(connCfg connectionConfig) error {
-	if connCfg.AzureAuthenticationType == emptyString {
-		return fmt.Errorf("AZURE_AUTHENTICATION_TYPE environment variable not set")
-	}
-	if connCfg.AzureAuthenticationType != "api_key" && connCfg.AzureAuthenticationType != "azure_ad" {
-		return fmt.Errorf("AZURE_AUTHENTICATION_TYPE must be either 'api_key' or 'azure_ad'")
+// setDefaults fills in the values that were not set in the config file.
+func setDefaults(cfg *Config) {
+	l := logger.GetLogger()
+	if cfg.LLMInstructions == emptyString {
+		l.Debug("LLMInstructions not set in config. Using default value." + defaultInstructions)
+		cfg.LLMInstructions = defaultInstructions
 	}
-	if connCfg.AzureAuthenticationType == "api_key" && connCfg.AzureOpenAIAPIKey == emptyString {
-		return fmt.Errorf("AZURE_OPENAI_API_KEY environment variable not set for api_key authentication type")
+	if cfg.PRInstructions == emptyString {
+		l.Debug("PRInstructions not set in config. Using default value.")
+		cfg.PRInstructions = defaultPRInstructions
 	}
-	if connCfg.AzureOpenAIEndpoint == emptyString {
-		return fmt.Errorf("AZURE_OPENAI_ENDPOINT environment variable not set")
+	if cfg.BehindPolicy == emptyString {
+		cfg.BehindPolicy = BehindPolicyWarn
 	}
-	if connCfg.AzureOpenAIDeploymentName == emptyString {
-		return fmt.Errorf("AZURE_OPENAI_DEPLOYMENT_NAME environment variable not set")
+	if cfg.ProtectedBranches == nil {
+		cfg.ProtectedBranches = defaultProtectedBranches
 	}
-	return nil
 }
 
-func validateOllamaConfig(connCfg connectionConfig) error {
-	if connCfg.OllamaAPIKey == emptyString {
-		return fmt.Errorf("OLLAMA_API_KEY environment variable not set")
+func validateConfig(cfg Config) error {
+	l := logger.GetLogger()
+	l.Debug("Validating config")
+	if err := ValidateBehindPolicy(cfg.BehindPolicy); err != nil {
+		return err
 	}
-	if connCfg.OllamaAPIBase == emptyString {
-		return fmt.Errorf("OLLAMA_API_BASE environment variable not set")
+	return validateConnectionConfig(cfg)
+}
+
+// ValidateBehindPolicy checks that the policy is one of the supported behind_policy values.
+func ValidateBehindPolicy(policy string) error {
+	switch policy {
+	case BehindPolicyWarn, BehindPolicyFail, BehindPolicyMergeBase:
+		return nil
+	default:
+		return fmt.Errorf("behind_policy must be one of %s, %s or %s. got: %s",
+			BehindPolicyWarn, BehindPolicyFail, BehindPolicyMergeBase, policy)
 	}
-	if connCfg.OllamaDeploymentName == emptyString {
-		return fmt.Errorf("OLLAMA_DEPLOYMENT_NAME environment variable not set")
+}
+
+func validateConnectionConfig(cfg Config) error {
+	l := logger.GetLogger()
+	l.Debug("Validating connection config from environment")
+	validate, ok := providerValidators[cfg.ConnectionConfig.ServiceProvider]
+	if !ok {
+		return fmt.Errorf(
+			"unsupported service provider. got: %s. Options are %s",
+			cfg.ConnectionConfig.ServiceProvider,
+			strings.Join(providerNames(), ", "),
+		)
 	}
-	return nil
+	return validate(cfg)
 }
 
 // CreateSampleConfig creates a sample configuration file
@@ -179,6 +336,22 @@ func CreateSampleConfig() error {
 		"You are a helpful assistant, that helps generating commit messages based on git diffs.",
 	)
 	viper.Set("should_commit", false)
+	viper.Set("pr_instructions", defaultPRInstructions)
+	// An empty base_branch uses the default branch of the remote
+	viper.Set("base_branch", emptyString)
+	viper.Set("behind_policy", BehindPolicyWarn)
+	viper.Set("timeout", defaultTimeout.String())
+	viper.Set("max_retries", defaultMaxRetries)
+	viper.Set("retry_backoff", defaultRetryBackoff.String())
+	viper.Set("retry_max_backoff", defaultRetryMaxBackoff.String())
+	viper.Set("protected_branches", defaultProtectedBranches)
+	viper.Set("lint", map[string]any{
+		"enabled":            false,
+		"types":              conventional.DefaultTypes,
+		"scopes":             []string{},
+		"max_subject_length": conventional.DefaultMaxSubjectLength,
+		"imperative":         true,
+	})
 	if err := viper.WriteConfigAs(".gic.yaml"); err != nil {
 		return err
 	}
@@ -190,17 +363,29 @@ func CreateSampleConfig() error {
 func CreateSampleDotEnv() error {
 	l := logger.GetLogger()
 	l.Debug("Creating sample .env configuration")
-	content := `SERVICE_PROVIDER=openai # openai, azure, ollama
+	content := `SERVICE_PROVIDER=openai # openai, azure, ollama, anthropic, gemini
 OPENAI_API_KEY=your_openai_api_key # Required if SERVICE_PROVIDER=openai
 OPENAI_API_BASE=https://api.openai.com/v1 # Required if SERVICE_PROVIDER=openai
 OPENAI_DEPLOYMENT_NAME=gpt-4o-mini # Value for OpenAI deployment name defaults to gpt-4o-mini
+OPENAI_ORG_ID=your_openai_organization # Optional OpenAI organization id
+OPENAI_PROJECT_ID=your_openai_project # Optional OpenAI project id
+OPENAI_EXTRA_HEADERS=X-Team=platform # Optional comma separated key=value headers sent to OPENAI_API_BASE
 AZURE_AUTHENTICATION_TYPE=api_key # api_key, azure_ad
 AZURE_OPENAI_API_KEY=your_azure_openai_api_key # Required if SERVICE_PROVIDER=azure or AZURE_AUTHENTICATION_TYPE=api_key
 AZURE_OPENAI_ENDPOINT=https://your-azure-endpoint # Required if SERVICE_PROVIDER=azure
 AZURE_OPENAI_DEPLOYMENT_NAME=your-deployment-name # Required if SERVICE_PROVIDER=azure
 OLLAMA_API_KEY=your_ollama_api_key # Required if SERVICE_PROVIDER=ollama
-OLLAMA_API_BASE=https://api.ollama.com/v1 # Required if SERVICE_PROVIDER=ollama
-OLLAMA_DEPLOYMENT_NAME=phi3 # Value for Ollama deployment name defaults to phi3`
+OLLAMA_API_BASE=http://localhost:11434 # Required if SERVICE_PROVIDER=ollama
+OLLAMA_DEPLOYMENT_NAME=phi3 # Value for Ollama deployment name defaults to phi3
+OLLAMA_EXTRA_HEADERS=X-Team=platform # Optional comma separated key=value headers sent to OLLAMA_API_BASE
+OLLAMA_CA_CERT=/path/to/ca.pem # Optional CA bundle to trust when OLLAMA_API_BASE uses an internal certificate
+ANTHROPIC_API_KEY=your_anthropic_api_key # Required if SERVICE_PROVIDER=anthropic
+ANTHROPIC_BASE_URL=https://api.anthropic.com # Value for Anthropic base url defaults to https://api.anthropic.com
+ANTHROPIC_MODEL=claude-3-5-haiku-latest # Value for Anthropic model defaults to claude-3-5-haiku-latest
+ANTHROPIC_MAX_TOKENS=1024 # Maximum tokens Anthropic may generate, defaults to 1024
+GEMINI_API_KEY=your_gemini_api_key # Required if SERVICE_PROVIDER=gemini
+GEMINI_API_BASE=https://generativelanguage.googleapis.com/v1beta # Gemini or Vertex endpoint, defaults to the Gemini API
+GEMINI_MODEL=gemini-1.5-flash # Value for Gemini model defaults to gemini-1.5-flash`
 	file, err := os.Create("sample.gic.env")
 	if err != nil {
 		return err
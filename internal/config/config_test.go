@@ -0,0 +1,128 @@
+package config_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gic/internal/config"
+	"gic/internal/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger()
+	os.Exit(m.Run())
+}
+
+func TestParseHeaders(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want map[string]string
+	}{
+		{name: "empty", raw: "", want: map[string]string{}},
+		{name: "single", raw: "X-Team=platform", want: map[string]string{"X-Team": "platform"}},
+		{
+			name: "several",
+			raw:  "X-Team=platform,X-Env=prod",
+			want: map[string]string{"X-Team": "platform", "X-Env": "prod"},
+		},
+		{
+			name: "whitespace",
+			raw:  " X-Team = platform , X-Env=prod ",
+			want: map[string]string{"X-Team": "platform", "X-Env": "prod"},
+		},
+		{name: "empty value", raw: "X-Empty=", want: map[string]string{"X-Empty": ""}},
+		{name: "value with equals", raw: "X-Query=a=b", want: map[string]string{"X-Query": "a=b"}},
+		{name: "empty pairs", raw: ",X-Team=platform,, ,", want: map[string]string{"X-Team": "platform"}},
+		{name: "missing equals", raw: "X-Team,X-Env=prod", want: map[string]string{"X-Env": "prod"}},
+		{name: "missing key", raw: "=platform, =prod", want: map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := config.ParseHeaders(tt.raw); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseHeaders(%q) = %v, want %v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIntFromEnv(t *testing.T) {
+	const defaultValue = 4096
+	tests := []struct {
+		name string
+		raw  string
+		want int
+	}{
+		{name: "unset", raw: "", want: defaultValue},
+		{name: "valid", raw: "1024", want: 1024},
+		{name: "whitespace", raw: " 1024 ", want: 1024},
+		{name: "blank", raw: "  ", want: defaultValue},
+		{name: "not a number", raw: "many", want: defaultValue},
+		{name: "decimal", raw: "10.5", want: defaultValue},
+		{name: "zero", raw: "0", want: defaultValue},
+		{name: "negative", raw: "-1", want: defaultValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GIC_TEST_INT", tt.raw)
+			if got := config.IntFromEnv("GIC_TEST_INT", defaultValue); got != tt.want {
+				t.Errorf("IntFromEnv with %q = %d, want %d", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateSampleConfigHasTheDefaults(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Error(err)
+		}
+	})
+	config.RegisterProvider("sample", func(config.Config) error { return nil })
+	t.Setenv("SERVICE_PROVIDER", "sample")
+
+	if err := config.CreateSampleConfig(); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(".gic.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{
+		"pr_instructions", "base_branch", "behind_policy", "timeout", "max_retries", "retry_backoff",
+		"retry_max_backoff", "protected_branches", "lint",
+	} {
+		if !strings.Contains(string(content), key+":") {
+			t.Errorf("expected %s in the sample config:\n%s", key, content)
+		}
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Timeout != 2*time.Minute || cfg.MaxRetries != 3 ||
+		cfg.RetryBackoff != time.Second || cfg.RetryMaxBackoff != 30*time.Second {
+		t.Errorf("unexpected timeout and retries %s, %d, %s, %s",
+			cfg.Timeout, cfg.MaxRetries, cfg.RetryBackoff, cfg.RetryMaxBackoff)
+	}
+	if !reflect.DeepEqual(cfg.ProtectedBranches, []string{"main", "master"}) {
+		t.Errorf("unexpected protected branches %q", cfg.ProtectedBranches)
+	}
+	if cfg.BaseBranch != "" || cfg.BehindPolicy != config.BehindPolicyWarn || cfg.PRInstructions == "" {
+		t.Errorf("unexpected pull request settings %q, %q, %q", cfg.BaseBranch, cfg.BehindPolicy, cfg.PRInstructions)
+	}
+	if cfg.Lint.Enabled || !cfg.Lint.Imperative || cfg.Lint.MaxSubjectLength == 0 || len(cfg.Lint.Types) == 0 {
+		t.Errorf("unexpected lint settings %+v", cfg.Lint)
+	}
+}
@@ -0,0 +1,7 @@
+package config
+
+// ParseHeaders and IntFromEnv expose the parsing of the environment to the tests.
+var (
+	ParseHeaders = parseHeaders
+	IntFromEnv   = intFromEnv
+)
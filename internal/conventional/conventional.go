@@ -0,0 +1,103 @@
+// Package conventional parses commit messages written in the Conventional Commits format and lints them.
+package conventional
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	emptyString = ""
+	// BreakingChange is the footer token announcing a breaking change. BREAKING-CHANGE is accepted as well.
+	BreakingChange = "BREAKING CHANGE"
+)
+
+// ErrInvalidHeader is returned by Parse when the first line is not "type(scope)!: description".
+var ErrInvalidHeader = errors.New(`the first line must look like "type(scope): description"`)
+
+var (
+	headerPattern = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^()]+)\))?(!)?: (\S.*)$`)
+	// footerPattern matches a footer line like "Refs: #12", "Closes #12" or "BREAKING CHANGE: ...".
+	footerPattern = regexp.MustCompile(`^([A-Za-z][A-Za-z-]*|` + BreakingChange + `)(: | #)(.*)$`)
+)
+
+// Footer is a git trailer at the end of a message, like "Refs: #12".
+type Footer struct {
+	Token string
+	Value string
+}
+
+// Message is a parsed commit message.
+type Message struct {
+	// Header is the whole first line.
+	Header   string
+	Type     string
+	Scopes   []string
+	Breaking bool
+	// Subject is the description following the type and scope in the header.
+	Subject string
+	Body    string
+	Footers []Footer
+}
+
+// Parse splits a commit message into its parts. The header, body and footers are always filled, and
+// the error wraps ErrInvalidHeader when the header does not follow the Conventional Commits format.
+func Parse(message string) (Message, error) {
+	message = strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n"))
+	header, rest, _ := strings.Cut(message, "\n")
+	parsed := Message{Header: strings.TrimSpace(header), Footers: []Footer{}}
+
+	paragraphs := strings.Split(strings.TrimSpace(rest), "\n\n")
+	if footers, ok := parseFooters(paragraphs[len(paragraphs)-1]); ok {
+		parsed.Footers = footers
+		paragraphs = paragraphs[:len(paragraphs)-1]
+	}
+	parsed.Body = strings.TrimSpace(strings.Join(paragraphs, "\n\n"))
+	for _, footer := range parsed.Footers {
+		if isBreakingToken(footer.Token) {
+			parsed.Breaking = true
+		}
+	}
+
+	match := headerPattern.FindStringSubmatch(parsed.Header)
+	if match == nil {
+		return parsed, fmt.Errorf("%w, got: %q", ErrInvalidHeader, parsed.Header)
+	}
+	parsed.Type = match[1]
+	if match[2] != emptyString {
+		for _, scope := range strings.Split(match[2], ",") {
+			parsed.Scopes = append(parsed.Scopes, strings.TrimSpace(scope))
+		}
+	}
+	parsed.Breaking = parsed.Breaking || match[3] == "!"
+	parsed.Subject = strings.TrimSpace(match[4])
+	return parsed, nil
+}
+
+// parseFooters parses a paragraph made only of footer lines. Indented lines continue the value of
+// the previous footer, the way git folds trailers.
+func parseFooters(paragraph string) ([]Footer, bool) {
+	var footers []Footer
+	for _, line := range strings.Split(paragraph, "\n") {
+		match := footerPattern.FindStringSubmatch(line)
+		switch {
+		case match != nil:
+			value := match[3]
+			if match[2] == " #" {
+				value = "#" + value
+			}
+			footers = append(footers, Footer{Token: match[1], Value: value})
+		case len(footers) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")):
+			footers[len(footers)-1].Value += "\n" + strings.TrimSpace(line)
+		default:
+			return nil, false
+		}
+	}
+	return footers, len(footers) > 0
+}
+
+func isBreakingToken(token string) bool {
+	return token == BreakingChange || token == "BREAKING-CHANGE"
+}
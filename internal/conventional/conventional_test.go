@@ -0,0 +1,78 @@
+package conventional_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"gic/internal/conventional"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    conventional.Message
+	}{
+		{
+			name:    "header only",
+			message: "fix: handle empty diffs\n",
+			want: conventional.Message{Header: "fix: handle empty diffs", Type: "fix", Subject: "handle empty diffs",
+				Footers: []conventional.Footer{}},
+		},
+		{
+			name:    "scopes and breaking marker",
+			message: "feat(cmd, llm)!: drop the legacy flags",
+			want: conventional.Message{Header: "feat(cmd, llm)!: drop the legacy flags", Type: "feat",
+				Scopes: []string{"cmd", "llm"}, Breaking: true, Subject: "drop the legacy flags",
+				Footers: []conventional.Footer{}},
+		},
+		{
+			name: "body and footers",
+			message: "feat(git): add --all\n\nStage every tracked file.\n\nLike git commit -a.\n\n" +
+				"Refs: #12\nCloses #13\nBREAKING CHANGE: the staged diff is\n  no longer the default",
+			want: conventional.Message{Header: "feat(git): add --all", Type: "feat", Scopes: []string{"git"},
+				Breaking: true, Subject: "add --all", Body: "Stage every tracked file.\n\nLike git commit -a.",
+				Footers: []conventional.Footer{
+					{Token: "Refs", Value: "#12"},
+					{Token: "Closes", Value: "#13"},
+					{Token: "BREAKING CHANGE", Value: "the staged diff is\nno longer the default"},
+				}},
+		},
+		{
+			name:    "last paragraph that is not only footers",
+			message: "docs: explain hooks\n\nNote: hooks run\nfor every commit",
+			want: conventional.Message{Header: "docs: explain hooks", Type: "docs", Subject: "explain hooks",
+				Body: "Note: hooks run\nfor every commit", Footers: []conventional.Footer{}},
+		},
+		{
+			name:    "windows line endings",
+			message: "chore: bump deps\r\n\r\nRefs: #1\r\n",
+			want: conventional.Message{Header: "chore: bump deps", Type: "chore", Subject: "bump deps",
+				Footers: []conventional.Footer{{Token: "Refs", Value: "#1"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := conventional.Parse(tt.message)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseInvalidHeader(t *testing.T) {
+	got, err := conventional.Parse("Here is a commit message for your changes:\n\nAdd the parser.\n\nRefs: #3")
+	if !errors.Is(err, conventional.ErrInvalidHeader) {
+		t.Fatalf("expected ErrInvalidHeader, got %v", err)
+	}
+	want := conventional.Message{Header: "Here is a commit message for your changes:", Body: "Add the parser.",
+		Footers: []conventional.Footer{{Token: "Refs", Value: "#3"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
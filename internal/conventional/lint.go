@@ -0,0 +1,167 @@
+package conventional
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// DefaultMaxSubjectLength is the longest first line allowed by DefaultRules.
+const DefaultMaxSubjectLength = 72
+
+// DefaultTypes are the types of the conventional changelog preset used by Semantic Release and commitlint.
+var DefaultTypes = []string{
+	"build", "chore", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test",
+}
+
+// Rules are the checks a message must pass besides the Conventional Commits format itself.
+type Rules struct {
+	// Types are the allowed types. Any type is allowed when it is empty.
+	Types []string
+	// Scopes are the allowed scopes. Any scope is allowed when it is empty. A scope is never required.
+	Scopes []string
+	// MaxSubjectLength is the longest first line allowed, 0 for no limit.
+	MaxSubjectLength int
+	// Imperative asks for the description to start with a verb in the imperative mood, like "add" and not "added".
+	Imperative bool
+}
+
+// DefaultRules returns the rules of the conventional changelog preset.
+func DefaultRules() Rules {
+	return Rules{Types: DefaultTypes, MaxSubjectLength: DefaultMaxSubjectLength, Imperative: true}
+}
+
+// LintError lists the problems found in a message.
+type LintError struct {
+	Problems []string
+}
+
+func (e *LintError) Error() string {
+	return "the commit message does not follow the commit rules:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Validate returns a *LintError when the message has problems, and nil otherwise.
+func Validate(message string, rules Rules) error {
+	if problems := Lint(message, rules); len(problems) > 0 {
+		return &LintError{Problems: problems}
+	}
+	return nil
+}
+
+// Lint returns the problems found in a message, in the order of the message.
+func Lint(message string, rules Rules) []string {
+	message = strings.ReplaceAll(message, "\r\n", "\n")
+	if strings.TrimSpace(message) == emptyString {
+		return []string{"the message is empty"}
+	}
+
+	var problems []string
+	if strings.HasPrefix(strings.TrimLeft(message, " \t"), "\n") {
+		problems = append(problems, "the message starts with a blank line")
+	}
+	if hasCodeFence(message) {
+		problems = append(problems, "the message contains a Markdown code fence")
+	}
+
+	parsed, err := Parse(message)
+	if errors.Is(err, ErrInvalidHeader) {
+		problems = append(problems, err.Error())
+	} else {
+		problems = append(problems, lintHeader(parsed, rules)...)
+	}
+	if rules.MaxSubjectLength > 0 && len([]rune(parsed.Header)) > rules.MaxSubjectLength {
+		problems = append(problems, fmt.Sprintf("the first line is %d characters long, the limit is %d",
+			len([]rune(parsed.Header)), rules.MaxSubjectLength))
+	}
+	return append(problems, lintStructure(message, parsed)...)
+}
+
+// lintHeader checks the type, scopes and description of a header in the Conventional Commits format.
+func lintHeader(parsed Message, rules Rules) []string {
+	var problems []string
+	if len(rules.Types) > 0 && !slices.Contains(rules.Types, parsed.Type) {
+		problems = append(problems, fmt.Sprintf("type %q is not one of %s", parsed.Type, strings.Join(rules.Types, ", ")))
+	}
+	if len(rules.Scopes) > 0 {
+		for _, scope := range parsed.Scopes {
+			if !slices.Contains(rules.Scopes, scope) {
+				problems = append(problems,
+					fmt.Sprintf("scope %q is not one of %s", scope, strings.Join(rules.Scopes, ", ")))
+			}
+		}
+	}
+	if rules.Imperative {
+		word, _, _ := strings.Cut(parsed.Subject, " ")
+		if !isImperative(word) {
+			problems = append(problems,
+				fmt.Sprintf(`the description should start in the imperative mood, like "add" and not %q`, word))
+		}
+	}
+	return problems
+}
+
+// lintStructure checks the blank lines separating the header, the body and the footers.
+func lintStructure(message string, parsed Message) []string {
+	var problems []string
+	lines := strings.Split(strings.TrimSpace(message), "\n")
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != emptyString {
+		problems = append(problems, "the first line must be followed by a blank line")
+	}
+	for _, line := range strings.Split(parsed.Body, "\n") {
+		if strings.HasPrefix(line, BreakingChange+":") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			problems = append(problems, "BREAKING CHANGE must be in the footers, after a blank line at the end of the message")
+			break
+		}
+	}
+	return problems
+}
+
+func hasCodeFence(message string) bool {
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+			return true
+		}
+	}
+	return false
+}
+
+// notPastTense are words ending in -ed or -ing that are imperative verbs nonetheless.
+var notPastTense = []string{
+	"bleed", "breed", "bring", "embed", "exceed", "feed", "need", "proceed", "seed", "shed", "shred", "speed",
+	"string", "succeed",
+}
+
+// commonVerbs are verbs often starting a description. "adds" or "fixes" is only flagged for these,
+// since plenty of imperative verbs end in s, like "focus" or "process".
+var commonVerbs = []string{
+	"add", "allow", "apply", "avoid", "bump", "change", "check", "clean", "copy", "create", "delete", "disable",
+	"document", "drop", "enable", "ensure", "extract", "fix", "handle", "implement", "improve", "introduce", "log",
+	"make", "merge", "modify", "move", "prevent", "print", "refactor", "remove", "rename", "replace", "return",
+	"revert", "run", "set", "show", "simplify", "skip", "specify", "support", "test", "update", "upgrade", "use",
+	"validate", "write",
+}
+
+// isImperative is a heuristic telling whether the first word of a description is a verb in the imperative mood.
+// It flags past tenses, gerunds and third person forms, like "added", "adding" and "adds".
+func isImperative(word string) bool {
+	word = strings.ToLower(strings.TrimRightFunc(word, unicode.IsPunct))
+	if slices.Contains(notPastTense, word) {
+		return true
+	}
+	if len(word) > 4 && (strings.HasSuffix(word, "ed") || strings.HasSuffix(word, "ing")) {
+		return false
+	}
+	for _, stem := range []string{
+		strings.TrimSuffix(word, "s"),
+		strings.TrimSuffix(word, "es"),
+		strings.TrimSuffix(word, "ies") + "y",
+	} {
+		if stem != word && slices.Contains(commonVerbs, stem) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package conventional_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"gic/internal/conventional"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		rules   conventional.Rules
+		want    []string
+	}{
+		{
+			name:    "valid",
+			message: "feat(llm): add the gemini provider\n\nUse the generateContent API.\n\nRefs: #7",
+			rules:   conventional.DefaultRules(),
+		},
+		{
+			name:    "empty",
+			message: "\n \n",
+			rules:   conventional.DefaultRules(),
+			want:    []string{"the message is empty"},
+		},
+		{
+			name:    "prose",
+			message: "Sure! Here is the commit message:\n\nfeat: add the parser",
+			rules:   conventional.DefaultRules(),
+			want: []string{
+				`the first line must look like "type(scope): description", got: "Sure! Here is the commit message:"`,
+			},
+		},
+		{
+			name:    "code fence",
+			message: "```\nfeat: add the parser\n```",
+			rules:   conventional.DefaultRules(),
+			want: []string{
+				"the message contains a Markdown code fence",
+				`the first line must look like "type(scope): description", got: "` + "```" + `"`,
+				"the first line must be followed by a blank line",
+			},
+		},
+		{
+			name:    "type and scope",
+			message: "feature(ui): add a button",
+			rules:   conventional.Rules{Types: []string{"feat", "fix"}, Scopes: []string{"cmd", "git"}},
+			want: []string{
+				`type "feature" is not one of feat, fix`,
+				`scope "ui" is not one of cmd, git`,
+			},
+		},
+		{
+			name:    "any type and scope",
+			message: "feature(ui): add a button",
+		},
+		{
+			name:    "subject length",
+			message: "fix: make the first line of this commit message a little bit too long",
+			rules:   conventional.Rules{MaxSubjectLength: 50},
+			want:    []string{"the first line is 69 characters long, the limit is 50"},
+		},
+		{
+			name:    "past tense",
+			message: "fix: Fixed the retries",
+			rules:   conventional.Rules{Imperative: true},
+			want:    []string{`the description should start in the imperative mood, like "add" and not "Fixed"`},
+		},
+		{
+			name:    "third person",
+			message: "fix: updates the retries",
+			rules:   conventional.Rules{Imperative: true},
+			want:    []string{`the description should start in the imperative mood, like "add" and not "updates"`},
+		},
+		{
+			name:    "imperative verbs looking like other forms",
+			message: "perf: speed up the diff and embed the prompt",
+			rules:   conventional.Rules{Imperative: true},
+		},
+		{
+			name:    "blank lines",
+			message: "\nfeat: add a flag\nIt is off by default.\nBREAKING CHANGE: the config changes",
+			rules:   conventional.DefaultRules(),
+			want: []string{
+				"the message starts with a blank line",
+				"the first line must be followed by a blank line",
+				"BREAKING CHANGE must be in the footers, after a blank line at the end of the message",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := conventional.Lint(tt.message, tt.rules)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := conventional.Validate("fix: handle empty diffs", conventional.DefaultRules()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	err := conventional.Validate("Fixed things.", conventional.DefaultRules())
+	var lintErr *conventional.LintError
+	if !errors.As(err, &lintErr) || len(lintErr.Problems) != 1 {
+		t.Fatalf("expected a LintError with one problem, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package git_test
+
+import (
+	"strings"
+	"testing"
+
+	"gic/internal/config"
+	"gic/internal/git"
+)
+
+func TestAmendDiffIncludesHeadAndStagedChanges(t *testing.T) {
+	newTestRepo(t)
+	commitFile(t, "a.go", "package a\n", "feat: add a")
+	writeFile(t, "a_test.go", "package a\n")
+	runGit(t, "add", "a_test.go")
+
+	diff, err := git.GetGitDiff(config.Config{Amend: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"a.go", "a_test.go"} {
+		if !strings.Contains(diff, file) {
+			t.Errorf("expected the diff to contain %s, got %q", file, diff)
+		}
+	}
+	if strings.Contains(diff, "README.md") {
+		t.Errorf("expected older commits to be left out, got %q", diff)
+	}
+}
+
+func TestAmendDiffOfRootCommit(t *testing.T) {
+	newTestRepo(t)
+
+	diff, err := git.GetGitDiff(config.Config{Amend: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff, "README.md") {
+		t.Errorf("expected the diff of the root commit, got %q", diff)
+	}
+}
+
+func TestCommitAmend(t *testing.T) {
+	newTestRepo(t)
+	commitFile(t, "a.go", "package a\n", "wip")
+	writeFile(t, "a_test.go", "package a\n")
+	runGit(t, "add", "a_test.go")
+
+	if err := git.Commit("feat: add a with tests", config.Config{ShouldCommit: true, Amend: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := runGit(t, "rev-list", "--count", "HEAD"); got != "2" {
+		t.Errorf("expected HEAD to be amended, got %s commits", got)
+	}
+	if got := runGit(t, "log", "-1", "--format=%s"); got != "feat: add a with tests" {
+		t.Errorf("unexpected message %q", got)
+	}
+	if got := runGit(t, "show", "--name-only", "--format=", "HEAD"); !strings.Contains(got, "a_test.go") {
+		t.Errorf("expected the staged file in the amended commit, got %q", got)
+	}
+}
+
+func TestAmendAllDiffIncludesHeadAndWorkingTreeChanges(t *testing.T) {
+	newTestRepo(t)
+	commitFile(t, "a.go", "package a\n", "feat: add a")
+	writeFile(t, "README.md", "# changed\n")
+
+	diff, err := git.GetGitDiff(config.Config{Amend: true, All: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"a.go", "README.md"} {
+		if !strings.Contains(diff, file) {
+			t.Errorf("expected the diff to contain %s, got %q", file, diff)
+		}
+	}
+
+	if err := git.Commit("feat: add a", config.Config{ShouldCommit: true, Amend: true, All: true}); err != nil {
+		t.Fatal(err)
+	}
+	got := runGit(t, "show", "--name-only", "--format=", "HEAD")
+	if !strings.Contains(got, "a.go") || !strings.Contains(got, "README.md") {
+		t.Errorf("expected HEAD and the working tree changes in the amended commit, got %q", got)
+	}
+}
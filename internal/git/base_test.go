@@ -0,0 +1,180 @@
+package git_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gic/internal/config"
+	"gic/internal/git"
+)
+
+// addRemote creates a bare repository, registers it as remote and pushes the current HEAD to branch,
+// which becomes the default branch of the remote.
+func addRemote(t *testing.T, remote, branch string) {
+	t.Helper()
+	bare := filepath.Join(t.TempDir(), remote+".git")
+	runGit(t, "init", "-q", "--bare", "-b", branch, bare)
+	runGit(t, "remote", "add", remote, bare)
+	runGit(t, "push", "-q", remote, "HEAD:refs/heads/"+branch)
+	runGit(t, "remote", "set-head", remote, "--auto")
+}
+
+func TestResolveBase(t *testing.T) {
+	newTestRepo(t)
+	addRemote(t, "origin", "develop")
+	addRemote(t, "upstream", "trunk")
+
+	tests := []struct {
+		name       string
+		cfg        config.Config
+		wantRemote string
+		wantBranch string
+	}{
+		{name: "detects the default branch", wantRemote: "origin", wantBranch: "develop"},
+		{name: "configured branch", cfg: config.Config{BaseBranch: "release"}, wantRemote: "origin", wantBranch: "release"},
+		{name: "configured remote", cfg: config.Config{Remote: "upstream"}, wantRemote: "upstream", wantBranch: "trunk"},
+		{
+			name:       "remote in the branch",
+			cfg:        config.Config{BaseBranch: "upstream/master"},
+			wantRemote: "upstream",
+			wantBranch: "master",
+		},
+		{
+			name:       "slash in a branch name",
+			cfg:        config.Config{BaseBranch: "release/v2"},
+			wantRemote: "origin",
+			wantBranch: "release/v2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remote, branch, err := git.ResolveBase(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if remote != tt.wantRemote || branch != tt.wantBranch {
+				t.Errorf("expected %s/%s, got %s/%s", tt.wantRemote, tt.wantBranch, remote, branch)
+			}
+		})
+	}
+}
+
+func TestResolveBaseWithoutRemoteHead(t *testing.T) {
+	newTestRepo(t)
+	if _, _, err := git.ResolveBase(config.Config{}); err == nil {
+		t.Fatal("expected an error when the default branch can not be detected")
+	}
+}
+
+func TestPullRequestDiffAgainstDefaultBranch(t *testing.T) {
+	newTestRepo(t)
+	addRemote(t, "origin", "develop")
+	runGit(t, "checkout", "-q", "-b", "feature")
+	writeFile(t, "feature.go", "package feature\n")
+	runGit(t, "add", "feature.go")
+	runGit(t, "commit", "-q", "-m", "feat: add feature")
+
+	diff, err := pullRequestDiff(config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff, "feature.go") {
+		t.Fatalf("expected the diff against origin/develop to contain feature.go, got %q", diff)
+	}
+}
+
+// pullRequestDiff returns the diff of the pull request GetPullRequest describes.
+func pullRequestDiff(cfg config.Config) (string, error) {
+	pr, err := git.GetPullRequest(cfg)
+	return pr.Diff, err
+}
+
+// newFeatureBranch sets up origin with develop as default branch, a local develop branch, and a feature
+// branch with one commit. Then develop moves ahead on the remote only.
+func newFeatureBranch(t *testing.T) {
+	t.Helper()
+	newTestRepo(t)
+	addRemote(t, "origin", "develop")
+	runGit(t, "branch", "develop")
+	runGit(t, "checkout", "-q", "-b", "feature")
+	writeFile(t, "feature.go", "package feature\n")
+	runGit(t, "add", "feature.go")
+	runGit(t, "commit", "-q", "-m", "feat: add feature")
+
+	runGit(t, "checkout", "-q", "-b", "upstream-work", "develop")
+	writeFile(t, "upstream.go", "package upstream\n")
+	runGit(t, "add", "upstream.go")
+	runGit(t, "commit", "-q", "-m", "feat: upstream work")
+	runGit(t, "push", "-q", "origin", "HEAD:develop")
+	runGit(t, "checkout", "-q", "feature")
+	runGit(t, "branch", "-q", "-D", "upstream-work")
+}
+
+func TestPullRequestDiffUsesMergeBase(t *testing.T) {
+	newFeatureBranch(t)
+
+	diff, err := pullRequestDiff(config.Config{BehindPolicy: config.BehindPolicyMergeBase})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff, "feature.go") {
+		t.Errorf("expected the diff to contain feature.go, got %q", diff)
+	}
+	if strings.Contains(diff, "upstream.go") {
+		t.Errorf("expected changes made on the base after the merge base to be left out, got %q", diff)
+	}
+}
+
+func TestBehindPolicy(t *testing.T) {
+	newFeatureBranch(t)
+
+	for policy, wantErr := range map[string]bool{
+		config.BehindPolicyWarn:      false,
+		config.BehindPolicyMergeBase: false,
+		config.BehindPolicyFail:      true,
+	} {
+		_, err := pullRequestDiff(config.Config{BehindPolicy: policy})
+		if (err != nil) != wantErr {
+			t.Errorf("behind_policy %s: expected error %v, got %v", policy, wantErr, err)
+		}
+	}
+}
+
+func TestNoFetch(t *testing.T) {
+	newFeatureBranch(t)
+	runGit(t, "remote", "set-url", "origin", filepath.Join(t.TempDir(), "missing.git"))
+
+	if _, err := pullRequestDiff(config.Config{}); err == nil {
+		t.Fatal("expected fetching from a missing remote to fail")
+	}
+	diff, err := pullRequestDiff(config.Config{NoFetch: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff, "feature.go") {
+		t.Errorf("expected the diff to contain feature.go, got %q", diff)
+	}
+}
+
+func TestGetPullRequest(t *testing.T) {
+	newFeatureBranch(t)
+	writeFile(t, "feature_test.go", "package feature\n")
+	runGit(t, "add", "feature_test.go")
+	runGit(t, "commit", "-q", "-m", "test: cover feature")
+
+	pr, err := git.GetPullRequest(config.Config{BehindPolicy: config.BehindPolicyMergeBase})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"feat: add feature", "test: cover feature"}
+	if strings.Join(pr.Commits, "|") != strings.Join(want, "|") {
+		t.Errorf("expected commits %q, got %q", want, pr.Commits)
+	}
+	if !strings.Contains(pr.DiffStat, "feature_test.go") || !strings.Contains(pr.DiffStat, "2 files changed") {
+		t.Errorf("unexpected diffstat %q", pr.DiffStat)
+	}
+	if !strings.Contains(pr.Diff, "feature.go") || strings.Contains(pr.Diff, "upstream.go") {
+		t.Errorf("expected only the changes of the branch, got %q", pr.Diff)
+	}
+}
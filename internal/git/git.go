@@ -3,8 +3,11 @@ package git
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"gic/internal/config"
 	"gic/internal/logger"
+	"os"
 	"os/exec"
 	"strings"
 )
@@ -15,6 +18,9 @@ const (
 	gitString       = "git"
 	diffOutputLimit = 2
 	diffsResults    = 1
+	defaultRemote   = "origin"
+	// emptyTree is the hash of the tree with no files, used as the parent of a root commit.
+	emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
 )
 
 // GetStagedChanges returns the staged changes in the git repository.
@@ -29,31 +35,270 @@ func getStagedChanges() (string, error) {
 	return string(out), nil
 }
 
-// getDiffWithMain returns the diff between the current branch and the main branch.
-func getDiffWithMain() (string, error) {
-	// check if it is behind and if it is, return error saying it is behind origin/main
-	_, err := isLocalMainBehind()
+// getWorkingTreeChanges returns the changes of the tracked files against base, staged or not,
+// followed by the untracked files when includeUntracked is set. Nothing is staged.
+func getWorkingTreeChanges(base string, includeUntracked bool) (string, error) {
+	out, err := exec.Command(gitString, "diff", base).Output()
 	if err != nil {
 		return emptyString, err
 	}
-	cmd := exec.Command(gitString, "diff", "origin/main")
-	output, err := cmd.Output()
+	diff := string(out)
+	if !includeUntracked {
+		return diff, nil
+	}
+
+	untracked, err := exec.Command(gitString, "ls-files", "-z", "--others", "--exclude-standard").Output()
 	if err != nil {
 		return emptyString, err
 	}
-	return string(output), nil
+	for _, file := range strings.Split(string(untracked), "\x00") {
+		if file == emptyString {
+			continue
+		}
+		// git diff --no-index exits with 1 when the files differ, which they always do here
+		out, err := exec.Command(gitString, "diff", "--no-index", "--", os.DevNull, file).Output()
+		var exitErr *exec.ExitError
+		if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+			return emptyString, fmt.Errorf("reading untracked file %s: %w", file, err)
+		}
+		diff += string(out)
+	}
+	return diff, nil
 }
 
-// IsLocalMainBehind checks if the local main branch is behind the origin main branch.
-func isLocalMainBehind() (bool, error) {
-	// Fetch the latest changes from the origin
-	cmd := exec.Command(gitString, "fetch", "origin")
-	if err := cmd.Run(); err != nil {
-		return false, err
+// stageWorkingTree stages what --all or --include-untracked described before it is committed.
+func stageWorkingTree(cfg config.Config) error {
+	args := []string{"add", "--update"}
+	if cfg.IncludeUntracked {
+		args = []string{"add", "--all"}
+	}
+	if out, err := exec.Command(gitString, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("staging the changes: %w: %s", err, out)
 	}
+	return nil
+}
 
-	// Compare the local main branch with the origin main branch
-	cmd = exec.Command(gitString, "rev-list", "--left-right", "--count", "main...origin/main")
+// getAmendChanges returns the changes of HEAD combined with the staged changes,
+// which is what the commit contains once it is amended.
+func getAmendChanges() (string, error) {
+	cmd := exec.Command(gitString, "diff", "--cached", amendBase())
+	out, err := cmd.Output()
+	if err != nil {
+		return emptyString, err
+	}
+	return string(out), nil
+}
+
+// amendBase returns the parent of HEAD, or the empty tree when HEAD is a root commit.
+func amendBase() string {
+	return revisionOrEmptyTree("HEAD~1")
+}
+
+// revisionOrEmptyTree returns the revision, or the empty tree when it does not exist, as HEAD on an unborn branch.
+func revisionOrEmptyTree(revision string) string {
+	if err := exec.Command(gitString, "rev-parse", "--verify", "--quiet", revision).Run(); err != nil {
+		return emptyTree
+	}
+	return revision
+}
+
+// baseMergeBase fetches the remote unless no_fetch is set, applies the behind_policy and returns
+// the merge base of HEAD and the base branch.
+func baseMergeBase(cfg config.Config) (string, error) {
+	remote, branch, err := ResolveBase(cfg)
+	if err != nil {
+		return emptyString, err
+	}
+	if !cfg.NoFetch {
+		// Fetch the latest changes from the remote
+		if err := exec.Command(gitString, "fetch", remote).Run(); err != nil {
+			return emptyString, fmt.Errorf("fetching %s: %w. Use --no-fetch to work offline", remote, err)
+		}
+	}
+	if err := checkLocalBaseBehind(cfg, remote, branch); err != nil {
+		return emptyString, err
+	}
+	return MergeBase("HEAD", remote+"/"+branch)
+}
+
+// Changes holds what a description of several commits is generated from.
+type Changes struct {
+	// Commits are the subjects of the commits, oldest first.
+	Commits []string
+	// DiffStat is the output of git diff --stat for the changes.
+	DiffStat string
+	// Diff is the diff of the changes.
+	Diff string
+}
+
+// GetPullRequest returns the commits, diffstat and diff of the current branch compared to the base branch.
+func GetPullRequest(cfg config.Config) (Changes, error) {
+	mergeBase, err := baseMergeBase(cfg)
+	if err != nil {
+		return Changes{}, err
+	}
+	return changesBetween(mergeBase, "HEAD")
+}
+
+// GetRange returns the commits, diffstat and diff of a revision range.
+// A..B covers the commits reachable from B but not from A, and diffs A against B.
+// A...B diffs the merge base of A and B against B. A missing side of the range defaults to HEAD.
+func GetRange(revRange string) (Changes, error) {
+	from, to, err := parseRange(revRange)
+	if err != nil {
+		return Changes{}, err
+	}
+	return changesBetween(from, to)
+}
+
+// parseRange resolves a revision range to the revisions its diff is taken between.
+func parseRange(revRange string) (string, string, error) {
+	separator := ".."
+	if strings.Contains(revRange, "...") {
+		separator = "..."
+	}
+	from, to, found := strings.Cut(revRange, separator)
+	if !found {
+		return emptyString, emptyString, fmt.Errorf("invalid range %q, expected A..B or A...B", revRange)
+	}
+	if from == emptyString {
+		from = "HEAD"
+	}
+	if to == emptyString {
+		to = "HEAD"
+	}
+	for _, rev := range []string{from, to} {
+		if err := exec.Command(gitString, "rev-parse", "--verify", "--quiet", rev+"^{commit}").Run(); err != nil {
+			return emptyString, emptyString, fmt.Errorf("unknown revision %s in range %s", rev, revRange)
+		}
+	}
+	if separator == "..." {
+		mergeBase, err := MergeBase(from, to)
+		if err != nil {
+			return emptyString, emptyString, err
+		}
+		from = mergeBase
+	}
+	return from, to, nil
+}
+
+// changesBetween returns the commits in from..to, and the diffstat and diff from from to to.
+func changesBetween(from, to string) (Changes, error) {
+	out, err := exec.Command(gitString, "log", "--reverse", "--format=%s", from+".."+to).Output()
+	if err != nil {
+		return Changes{}, fmt.Errorf("listing the commits in %s..%s: %w", from, to, err)
+	}
+	var commits []string
+	for _, subject := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if subject != emptyString {
+			commits = append(commits, subject)
+		}
+	}
+	stat, err := exec.Command(gitString, "diff", "--stat", from, to).Output()
+	if err != nil {
+		return Changes{}, err
+	}
+	diff, err := exec.Command(gitString, "diff", from, to).Output()
+	if err != nil {
+		return Changes{}, err
+	}
+	return Changes{Commits: commits, DiffStat: strings.TrimRight(string(stat), "\n"), Diff: string(diff)}, nil
+}
+
+// MergeBase returns the best common ancestor of the two revisions.
+func MergeBase(a, b string) (string, error) {
+	cmd := exec.Command(gitString, "merge-base", a, b)
+	out, err := cmd.Output()
+	if err != nil {
+		return emptyString, fmt.Errorf("finding the merge base of %s and %s: %w", a, b, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// checkLocalBaseBehind applies the behind_policy when the local base branch is behind the remote one.
+// The diff is always taken from the merge base, so a stale local branch does not change it;
+// the policy decides whether the user is warned, stopped, or not bothered at all.
+func checkLocalBaseBehind(cfg config.Config, remote, branch string) error {
+	l := logger.GetLogger()
+	behind, err := isLocalBaseBehind(remote, branch)
+	if err != nil || !behind {
+		return err
+	}
+	switch cfg.BehindPolicy {
+	case config.BehindPolicyFail:
+		return fmt.Errorf("local %s is behind %s/%s. Pull it first, or set behind_policy to warn or merge-base",
+			branch, remote, branch)
+	case config.BehindPolicyMergeBase:
+		l.Debug("Local " + branch + " is behind " + remote + "/" + branch + ", using the merge base")
+	default:
+		l.Warn("Local " + branch + " is behind " + remote + "/" + branch + ". The diff is taken from the merge base")
+	}
+	return nil
+}
+
+// ResolveBase returns the remote and the branch pull requests are compared against.
+// The remote defaults to origin. The branch comes from the config, and can also name the remote as in
+// upstream/master. When it is not set, the default branch is read from refs/remotes/<remote>/HEAD.
+func ResolveBase(cfg config.Config) (string, string, error) {
+	remote := cfg.Remote
+	if remote == emptyString {
+		remote = defaultRemote
+	}
+	branch := cfg.BaseBranch
+	if prefix, rest, found := strings.Cut(branch, "/"); found && isRemote(prefix) {
+		remote, branch = prefix, rest
+	}
+	if branch != emptyString {
+		return remote, branch, nil
+	}
+
+	branch, err := defaultBranch(remote)
+	if err != nil {
+		return emptyString, emptyString, err
+	}
+	return remote, branch, nil
+}
+
+// defaultBranch reads the default branch of the remote from refs/remotes/<remote>/HEAD.
+func defaultBranch(remote string) (string, error) {
+	cmd := exec.Command(gitString, "symbolic-ref", "--short", "refs/remotes/"+remote+"/HEAD")
+	out, err := cmd.Output()
+	if err != nil {
+		return emptyString, fmt.Errorf(
+			"unable to detect the default branch of %s. Set base_branch in the config, use --base, "+
+				"or run 'git remote set-head %s --auto': %w",
+			remote, remote, err,
+		)
+	}
+	return strings.TrimPrefix(strings.TrimSpace(string(out)), remote+"/"), nil
+}
+
+// isRemote reports whether name is a configured remote.
+func isRemote(name string) bool {
+	cmd := exec.Command(gitString, "remote")
+	out, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+	for _, remote := range strings.Fields(string(out)) {
+		if remote == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isLocalBaseBehind checks if the local base branch is behind the same branch on the remote.
+// It reports false when there is no local copy of the base branch.
+func isLocalBaseBehind(remote, branch string) (bool, error) {
+	l := logger.GetLogger()
+	if err := exec.Command(gitString, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch).Run(); err != nil {
+		l.Debug("No local branch " + branch + ", skipping the behind check")
+		return false, nil
+	}
+
+	// Compare the local base branch with the remote base branch
+	cmd := exec.Command(gitString, "rev-list", "--left-right", "--count", branch+"..."+remote+"/"+branch)
 	out, err := cmd.Output()
 	if err != nil {
 		return false, err
@@ -62,21 +307,30 @@ func isLocalMainBehind() (bool, error) {
 	// Parse the output
 	parts := strings.Fields(string(out))
 	if len(parts) != diffOutputLimit {
-		return false, err
+		return false, fmt.Errorf("unexpected output comparing %s with %s/%s: %s", branch, remote, branch, out)
 	}
 
-	// Check if the local main branch is behind
+	// Check if the local base branch is behind
 	behind := parts[diffsResults] != "0"
 	return behind, nil
 }
 
-// Commit commits the staged changes with the generated message.
+// Commit commits the staged changes with the generated message, amending HEAD when Amend is set.
 // it will only print the message unless commit is set to true.
 func Commit(message string, cfg config.Config) error {
 	l := logger.GetLogger()
 	var err error
-	cmd := exec.Command(gitString, "commit", "-m", message)
-	if cfg.ShouldCommit && !cfg.PR {
+	args := []string{"commit", "-m", message}
+	if cfg.Amend {
+		args = append(args, "--amend")
+	}
+	cmd := exec.Command(gitString, args...)
+	if cfg.ShouldCommit {
+		if cfg.All || cfg.IncludeUntracked {
+			if err = stageWorkingTree(cfg); err != nil {
+				return err
+			}
+		}
 		l.Debug("ShouldCommit True. Committing changes...")
 		l.Debug("Commit message: " + message)
 		var stderr bytes.Buffer
@@ -92,9 +346,19 @@ func Commit(message string, cfg config.Config) error {
 // GetGitDiff returns the diff of the git repository based on the configuration.
 func GetGitDiff(cfg config.Config) (string, error) {
 	l := logger.GetLogger()
-	if cfg.PR {
-		l.Debug("Start getting diff with main branch")
-		return getDiffWithMain()
+	if cfg.All || cfg.IncludeUntracked {
+		l.Debug("Start getting the working tree changes")
+		// A branch without commits yet is compared against the empty tree
+		base := revisionOrEmptyTree("HEAD")
+		if cfg.Amend {
+			// The amended commit keeps the changes of HEAD, so they are described too
+			base = amendBase()
+		}
+		return getWorkingTreeChanges(base, cfg.IncludeUntracked)
+	}
+	if cfg.Amend {
+		l.Debug("Start getting the changes of HEAD and the staged changes")
+		return getAmendChanges()
 	}
 	l.Debug("Start getting staged changes")
 	return getStagedChanges()
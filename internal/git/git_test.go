@@ -1,10 +1,15 @@
 package git_test
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"gic/internal/config"
 	"gic/internal/git"
+	"gic/internal/logger"
 )
 
 // create a empty Config struct
@@ -14,7 +19,62 @@ func init() {
 	testConfig.PR = false
 }
 
+func TestMain(m *testing.M) {
+	logger.InitLogger()
+	os.Exit(m.Run())
+}
+
+// newTestRepo creates a repository with an initial commit in a temporary directory
+// and makes it the working directory for the rest of the test.
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Error(err)
+		}
+	})
+	runGit(t, "init", "-q", "-b", "main")
+	runGit(t, "config", "user.email", "gic@example.com")
+	runGit(t, "config", "user.name", "gic")
+	runGit(t, "config", "commit.gpgsign", "false")
+	writeFile(t, "README.md", "# test\n")
+	runGit(t, "add", "README.md")
+	runGit(t, "commit", "-q", "-m", "chore: initial commit")
+	return dir
+}
+
+func runGit(t *testing.T, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestGetStagedChanges(t *testing.T) {
+	newTestRepo(t)
+	writeFile(t, "main.go", "package main\n")
+	runGit(t, "add", "main.go")
+
 	diff, err := git.GetGitDiff(testConfig)
 	if err != nil {
 		t.Fatal(err)
@@ -0,0 +1,123 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	prepareCommitMsgHook = "prepare-commit-msg"
+	// chainedHookSuffix is appended to a prepare-commit-msg hook that existed before gic was installed.
+	// The gic hook runs it first so both keep working.
+	chainedHookSuffix = ".pre-gic"
+	hookMarker        = "# gic-hook"
+	hookFileMode      = 0o755
+)
+
+// hookScript runs a previously installed hook first, then fills the commit message buffer with gic.
+// gic hook run decides which commit sources to skip, see SkipsHook.
+const hookScript = `#!/bin/sh
+` + hookMarker + `
+# prepare-commit-msg hook installed by gic. Remove it with: gic hook uninstall
+hook_dir=$(dirname "$0")
+if [ -x "$hook_dir/` + prepareCommitMsgHook + chainedHookSuffix + `" ]; then
+	"$hook_dir/` + prepareCommitMsgHook + chainedHookSuffix + `" "$@" || exit $?
+fi
+
+command -v gic >/dev/null 2>&1 || exit 0
+gic hook run "$@" || true
+`
+
+// HooksDir returns the directory git runs hooks from, honoring core.hooksPath.
+func HooksDir() (string, error) {
+	cmd := exec.Command(gitString, "rev-parse", "--git-path", "hooks")
+	out, err := cmd.Output()
+	if err != nil {
+		return emptyString, err
+	}
+	return filepath.Abs(strings.TrimSpace(string(out)))
+}
+
+// InstallHook writes the gic prepare-commit-msg hook. An existing hook that was not installed by gic
+// is kept next to it and run before gic.
+func InstallHook() (string, error) {
+	dir, err := HooksDir()
+	if err != nil {
+		return emptyString, err
+	}
+	if err := os.MkdirAll(dir, hookFileMode); err != nil {
+		return emptyString, err
+	}
+	path := filepath.Join(dir, prepareCommitMsgHook)
+	chained := path + chainedHookSuffix
+
+	installed, err := isGicHook(path)
+	if err != nil {
+		return emptyString, err
+	}
+	if _, err := os.Stat(path); err == nil && !installed {
+		if _, err := os.Stat(chained); err == nil {
+			return emptyString, fmt.Errorf("%s already exists, refusing to overwrite it", chained)
+		}
+		if err := os.Rename(path, chained); err != nil {
+			return emptyString, err
+		}
+	}
+	// #nosec G306 -- git hooks have to be executable
+	if err := os.WriteFile(path, []byte(hookScript), hookFileMode); err != nil {
+		return emptyString, err
+	}
+	return path, nil
+}
+
+// UninstallHook removes the gic prepare-commit-msg hook and puts back the hook it replaced, if any.
+func UninstallHook() (string, error) {
+	dir, err := HooksDir()
+	if err != nil {
+		return emptyString, err
+	}
+	path := filepath.Join(dir, prepareCommitMsgHook)
+
+	installed, err := isGicHook(path)
+	if err != nil {
+		return emptyString, err
+	}
+	if !installed {
+		return emptyString, fmt.Errorf("no gic hook installed in %s", dir)
+	}
+	if err := os.Remove(path); err != nil {
+		return emptyString, err
+	}
+	if err := os.Rename(path+chainedHookSuffix, path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return emptyString, err
+	}
+	return path, nil
+}
+
+// isGicHook reports whether the hook at path was installed by gic. A missing hook is not an error.
+func isGicHook(path string) (bool, error) {
+	content, err := os.ReadFile(path) // #nosec G304 -- path is inside the repository's hooks directory
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return strings.Contains(string(content), hookMarker), nil
+}
+
+// SkipsHook reports whether the prepare-commit-msg hook should leave the message alone for a commit source,
+// which is the case when the message was given with -m or -F, or comes from a merge, a squash or an amend.
+func SkipsHook(source string) bool {
+	switch source {
+	case "message", "merge", "squash", "commit":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,117 @@
+package git_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gic/internal/git"
+)
+
+func TestInstallAndUninstallHook(t *testing.T) {
+	newTestRepo(t)
+
+	path, err := git.InstallHook()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0o100 == 0 {
+		t.Errorf("expected %s to be executable", path)
+	}
+	if !strings.HasSuffix(path, filepath.Join(".git", "hooks", "prepare-commit-msg")) {
+		t.Errorf("unexpected hook path %s", path)
+	}
+
+	// installing twice replaces the gic hook instead of chaining it to itself
+	if _, err := git.InstallHook(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".pre-gic"); !os.IsNotExist(err) {
+		t.Errorf("expected no chained hook after installing twice, got %v", err)
+	}
+
+	if _, err := git.UninstallHook(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, got %v", path, err)
+	}
+}
+
+func TestInstallHookChainsExistingHook(t *testing.T) {
+	newTestRepo(t)
+	existing := "#!/bin/sh\necho existing\n"
+	writeFile(t, ".git/hooks/prepare-commit-msg", existing)
+
+	path, err := git.InstallHook()
+	if err != nil {
+		t.Fatal(err)
+	}
+	chained, err := os.ReadFile(path + ".pre-gic")
+	if err != nil {
+		t.Fatalf("expected the existing hook to be kept: %v", err)
+	}
+	if string(chained) != existing {
+		t.Errorf("unexpected chained hook content %q", chained)
+	}
+
+	if _, err := git.UninstallHook(); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(restored) != existing {
+		t.Errorf("expected the existing hook to be restored, got %q", restored)
+	}
+}
+
+func TestInstallHookHonorsHooksPath(t *testing.T) {
+	dir := newTestRepo(t)
+	runGit(t, "config", "core.hooksPath", "custom-hooks")
+
+	path, err := git.InstallHook()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(dir, "custom-hooks", "prepare-commit-msg")
+	resolved, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDir, err := filepath.EvalSymlinks(filepath.Dir(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved != wantDir {
+		t.Errorf("expected hook in %s, got %s", want, path)
+	}
+}
+
+func TestUninstallHookWithoutGicHook(t *testing.T) {
+	newTestRepo(t)
+	if _, err := git.UninstallHook(); err == nil {
+		t.Fatal("expected an error when no gic hook is installed")
+	}
+}
+
+func TestSkipsHook(t *testing.T) {
+	for source, want := range map[string]bool{
+		"":         false,
+		"template": false,
+		"message":  true,
+		"merge":    true,
+		"squash":   true,
+		"commit":   true,
+	} {
+		if got := git.SkipsHook(source); got != want {
+			t.Errorf("SkipsHook(%q) = %v, want %v", source, got, want)
+		}
+	}
+}
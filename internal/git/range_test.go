@@ -0,0 +1,70 @@
+package git_test
+
+import (
+	"strings"
+	"testing"
+
+	"gic/internal/git"
+)
+
+// commitFile writes name with content, stages it and commits it with message.
+func commitFile(t *testing.T, name, content, message string) {
+	t.Helper()
+	writeFile(t, name, content)
+	runGit(t, "add", name)
+	runGit(t, "commit", "-q", "-m", message)
+}
+
+func TestGetRange(t *testing.T) {
+	newTestRepo(t)
+	runGit(t, "tag", "v1")
+	commitFile(t, "a.go", "package a\n", "feat: add a")
+	commitFile(t, "b.go", "package b\n", "fix: add b")
+
+	changes, err := git.GetRange("v1..HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(changes.Commits, "|"); got != "feat: add a|fix: add b" {
+		t.Errorf("unexpected commits %q", got)
+	}
+	if !strings.Contains(changes.DiffStat, "2 files changed") {
+		t.Errorf("unexpected diffstat %q", changes.DiffStat)
+	}
+	if !strings.Contains(changes.Diff, "a.go") || !strings.Contains(changes.Diff, "b.go") {
+		t.Errorf("expected the diff to contain a.go and b.go, got %q", changes.Diff)
+	}
+
+	changes, err = git.GetRange("HEAD~1..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes.Commits) != 1 || strings.Contains(changes.Diff, "a.go") {
+		t.Errorf("expected only the last commit, got %q", changes.Commits)
+	}
+}
+
+func TestGetRangeFromMergeBase(t *testing.T) {
+	newTestRepo(t)
+	runGit(t, "checkout", "-q", "-b", "feature")
+	commitFile(t, "feature.go", "package feature\n", "feat: add feature")
+	runGit(t, "checkout", "-q", "main")
+	commitFile(t, "main.go", "package main\n", "chore: work on main")
+
+	changes, err := git.GetRange("main...feature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(changes.Diff, "feature.go") || strings.Contains(changes.Diff, "main.go") {
+		t.Errorf("expected only the changes of feature, got %q", changes.Diff)
+	}
+}
+
+func TestGetRangeRejectsInvalidRanges(t *testing.T) {
+	newTestRepo(t)
+	for _, revRange := range []string{"HEAD", "missing..HEAD"} {
+		if _, err := git.GetRange(revRange); err == nil {
+			t.Errorf("expected an error for %q", revRange)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"gic/internal/logger"
+)
+
+// RewordCommit is a commit whose message gic reword regenerates.
+type RewordCommit struct {
+	Hash    string
+	Message string
+	// Diff is the diff of the commit against its parent.
+	Diff string
+}
+
+// Subject returns the first line of the commit message.
+func (c RewordCommit) Subject() string {
+	subject, _, _ := strings.Cut(c.Message, "\n")
+	return subject
+}
+
+// ShortHashLength is the length of the abbreviated commit hashes shown to the user.
+const ShortHashLength = 7
+
+// Fields of the commit read by replayCommit, in the order of its --format.
+const (
+	authorNameField = iota
+	authorEmailField
+	authorDateField
+	treeField
+	commitFields
+)
+
+// GetReword returns the commits of the revision range, oldest first, with their messages and diffs.
+// The range must end at HEAD and must not contain merge commits. A single revision A means A..HEAD.
+func GetReword(revRange string) ([]RewordCommit, error) {
+	commitRange, err := rewordRange(revRange)
+	if err != nil {
+		return nil, err
+	}
+	hashes, err := RevList(commitRange)
+	if err != nil {
+		return nil, err
+	}
+	if len(hashes) == 0 {
+		return nil, fmt.Errorf("no commits in %s", revRange)
+	}
+	messages, err := CommitMessages(commitRange)
+	if err != nil {
+		return nil, err
+	}
+	commits := make([]RewordCommit, 0, len(hashes))
+	for i, hash := range hashes {
+		diff, err := exec.Command(gitString, "diff-tree", "-p", "--root", "--no-commit-id", hash).Output()
+		if err != nil {
+			return nil, fmt.Errorf("reading the diff of %s: %w", hash, err)
+		}
+		commits = append(commits, RewordCommit{Hash: hash, Message: messages[i], Diff: string(diff)})
+	}
+	return commits, nil
+}
+
+// rewordRange checks that the revision range ends at HEAD and has no merge commits, and returns it as from..to.
+func rewordRange(revRange string) (string, error) {
+	if !strings.Contains(revRange, "..") {
+		revRange += "..HEAD"
+	}
+	from, to, err := parseRange(revRange)
+	if err != nil {
+		return emptyString, err
+	}
+	head, err := revParse("HEAD")
+	if err != nil {
+		return emptyString, err
+	}
+	if tip, err := revParse(to); err != nil || tip != head {
+		return emptyString, fmt.Errorf("the range %s must end at HEAD", revRange)
+	}
+
+	commitRange := from + ".." + to
+	merges, err := exec.Command(gitString, "rev-list", "--merges", commitRange).Output()
+	if err != nil {
+		return emptyString, err
+	}
+	if strings.TrimSpace(string(merges)) != emptyString {
+		return emptyString, fmt.Errorf("the range %s contains merge commits, which can not be reworded", revRange)
+	}
+	return commitRange, nil
+}
+
+// CheckRewritable refuses to rewrite commits on a protected branch, or commits that were already pushed
+// to any remote.
+func CheckRewritable(commits []RewordCommit, protected []string) error {
+	out, err := exec.Command(gitString, "symbolic-ref", "--short", "-q", "HEAD").Output()
+	if err == nil {
+		branch := strings.TrimSpace(string(out))
+		if slices.Contains(protected, branch) {
+			return fmt.Errorf("%s is a protected branch. Use --force to rewrite it anyway", branch)
+		}
+	}
+
+	// Every commit is an ancestor of the ones after it, so the range was pushed if its oldest commit was.
+	oldest := commits[0].Hash
+	remotes, err := exec.Command(gitString, "for-each-ref", "--format=%(refname:short)", "--contains", oldest,
+		"refs/remotes").Output()
+	if err != nil {
+		return err
+	}
+	if pushed := strings.Fields(string(remotes)); len(pushed) > 0 {
+		return fmt.Errorf("%s was already pushed to %s. Use --force to rewrite it anyway",
+			oldest[:ShortHashLength], strings.Join(pushed, ", "))
+	}
+	return nil
+}
+
+// Reword replays the commits with the new messages on top of the parent of the oldest one, keeping their trees,
+// authors and dates, and moves the current branch to the last replayed commit. It returns the new HEAD.
+func Reword(commits []RewordCommit, messages []string) (string, error) {
+	l := logger.GetLogger()
+	if len(commits) == 0 || len(commits) != len(messages) {
+		return emptyString, fmt.Errorf("expected one message for each of the %d commits, got %d",
+			len(commits), len(messages))
+	}
+	parent, err := revParse(commits[0].Hash + "^")
+	if err != nil {
+		// The oldest commit is a root commit
+		parent = emptyString
+	}
+
+	for i, commit := range commits {
+		parent, err = replayCommit(commit.Hash, parent, messages[i])
+		if err != nil {
+			return emptyString, err
+		}
+		l.Debug("Reworded commit", "old", commit.Hash, "new", parent)
+	}
+
+	oldHead := commits[len(commits)-1].Hash
+	if err := exec.Command(gitString, "update-ref", "-m", "gic reword", "HEAD", parent, oldHead).Run(); err != nil {
+		return emptyString, fmt.Errorf("moving HEAD to the reworded commits: %w", err)
+	}
+	return parent, nil
+}
+
+// replayCommit creates a copy of the commit with the message on top of parent, and returns its hash.
+func replayCommit(hash, parent, message string) (string, error) {
+	out, err := exec.Command(gitString, "log", "-1", "--format=%an%x00%ae%x00%aD%x00%T", hash).Output()
+	if err != nil {
+		return emptyString, err
+	}
+	fields := strings.Split(strings.TrimSpace(string(out)), "\x00")
+	if len(fields) != commitFields {
+		return emptyString, fmt.Errorf("unexpected output reading commit %s: %q", hash, out)
+	}
+
+	args := []string{"commit-tree", fields[treeField], "-F", "-"}
+	if parent != emptyString {
+		args = append(args, "-p", parent)
+	}
+	cmd := exec.Command(gitString, args...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+fields[authorNameField],
+		"GIT_AUTHOR_EMAIL="+fields[authorEmailField],
+		"GIT_AUTHOR_DATE="+fields[authorDateField],
+	)
+	cmd.Stdin = strings.NewReader(message)
+	replayed, err := cmd.Output()
+	if err != nil {
+		return emptyString, fmt.Errorf("replaying commit %s: %w", hash, err)
+	}
+	return strings.TrimSpace(string(replayed)), nil
+}
+
+// revParse returns the hash of the commit the revision points to.
+func revParse(rev string) (string, error) {
+	out, err := exec.Command(gitString, "rev-parse", "--verify", "--quiet", rev+"^{commit}").Output()
+	if err != nil {
+		return emptyString, fmt.Errorf("unknown revision %s", rev)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,94 @@
+package git_test
+
+import (
+	"strings"
+	"testing"
+
+	"gic/internal/git"
+)
+
+func TestGetReword(t *testing.T) {
+	newTestRepo(t)
+	commitFile(t, "a.go", "package a\n", "wip")
+	commitFile(t, "b.go", "package b\n", "fix")
+
+	commits, err := git.GetReword("HEAD~2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 2 || commits[0].Subject() != "wip" || commits[1].Subject() != "fix" {
+		t.Fatalf("unexpected commits %+v", commits)
+	}
+	if !strings.Contains(commits[0].Diff, "a.go") || strings.Contains(commits[0].Diff, "b.go") {
+		t.Errorf("expected the diff of the commit alone, got %q", commits[0].Diff)
+	}
+
+	if _, err := git.GetReword("HEAD~2..HEAD~1"); err == nil {
+		t.Error("expected an error for a range that does not end at HEAD")
+	}
+}
+
+func TestGetRewordRejectsMerges(t *testing.T) {
+	newTestRepo(t)
+	base := runGit(t, "rev-parse", "HEAD")
+	runGit(t, "checkout", "-q", "-b", "feature")
+	commitFile(t, "a.go", "package a\n", "wip")
+	runGit(t, "checkout", "-q", "main")
+	commitFile(t, "b.go", "package b\n", "fix")
+	runGit(t, "merge", "-q", "--no-edit", "feature")
+
+	if _, err := git.GetReword(base + ".."); err == nil {
+		t.Fatal("expected an error for a range with a merge commit")
+	}
+}
+
+func TestReword(t *testing.T) {
+	newTestRepo(t)
+	runGit(t, "checkout", "-q", "-b", "feature")
+	commitFile(t, "a.go", "package a\n", "wip")
+	commitFile(t, "b.go", "package b\n", "fix")
+	tree := runGit(t, "rev-parse", "HEAD^{tree}")
+	author := runGit(t, "log", "-1", "--format=%an <%ae> %ad", "HEAD~1")
+
+	commits, err := git.GetReword("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := git.CheckRewritable(commits, []string{"main"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := git.Reword(commits, []string{"feat: add a", "feat: add b\n\nwith a body"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := runGit(t, "log", "--format=%s", "main..HEAD"); got != "feat: add b\nfeat: add a" {
+		t.Errorf("unexpected messages %q", got)
+	}
+	if got := runGit(t, "rev-parse", "HEAD^{tree}"); got != tree {
+		t.Errorf("expected the tree to be kept, got %s instead of %s", got, tree)
+	}
+	if got := runGit(t, "log", "-1", "--format=%an <%ae> %ad", "HEAD~1"); got != author {
+		t.Errorf("expected the author to be kept, got %q instead of %q", got, author)
+	}
+	if got := runGit(t, "symbolic-ref", "--short", "HEAD"); got != "feature" {
+		t.Errorf("expected to stay on feature, got %s", got)
+	}
+}
+
+func TestCheckRewritable(t *testing.T) {
+	newTestRepo(t)
+	commitFile(t, "a.go", "package a\n", "wip")
+	commits, err := git.GetReword("HEAD~1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := git.CheckRewritable(commits, []string{"main"}); err == nil {
+		t.Error("expected an error on a protected branch")
+	}
+
+	runGit(t, "checkout", "-q", "-b", "feature")
+	addRemote(t, "origin", "feature")
+	if err := git.CheckRewritable(commits, []string{"main"}); err == nil {
+		t.Error("expected an error for pushed commits")
+	}
+}
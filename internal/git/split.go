@@ -0,0 +1,133 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"gic/internal/logger"
+)
+
+const fileDiffPrefix = "diff --git "
+
+// StagedFile is the staged patch of a single file.
+type StagedFile struct {
+	Path  string
+	Patch string
+}
+
+// GetStagedFiles returns the staged changes split per file. The patches include binary changes,
+// so they can be applied to the index again.
+func GetStagedFiles() ([]StagedFile, error) {
+	out, err := exec.Command(gitString, "diff", "--cached", "--binary", "--no-color").Output()
+	if err != nil {
+		return nil, err
+	}
+	var files []StagedFile
+	for _, patch := range splitPatches(string(out)) {
+		files = append(files, StagedFile{Path: patchPath(patch), Patch: patch})
+	}
+	return files, nil
+}
+
+// splitPatches splits a diff into the patches of each file.
+func splitPatches(diff string) []string {
+	var patches []string
+	lines := strings.SplitAfter(diff, "\n")
+	var current strings.Builder
+	for _, line := range lines {
+		if strings.HasPrefix(line, fileDiffPrefix) && current.Len() > 0 {
+			patches = append(patches, current.String())
+			current.Reset()
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 {
+		patches = append(patches, current.String())
+	}
+	return patches
+}
+
+// patchPath returns the path of the file a patch changes, the new path for renames.
+func patchPath(patch string) string {
+	header, _, _ := strings.Cut(patch, "\n")
+	header = strings.TrimPrefix(header, fileDiffPrefix)
+	// git quotes paths with special or non-ASCII characters in C style, as in "b/caf\303\251.go"
+	if i := strings.LastIndex(header, ` "b/`); i >= 0 {
+		if path, err := strconv.Unquote(header[i+1:]); err == nil {
+			return strings.TrimPrefix(path, "b/")
+		}
+	}
+	if i := strings.LastIndex(header, " b/"); i >= 0 {
+		return header[i+len(" b/"):]
+	}
+	return header
+}
+
+// CommitGroups unstages everything and then commits each group of files with its message, staging the
+// patches of the group with git apply --cached. When a commit fails, the files that were not committed
+// yet are staged again.
+func CommitGroups(groups [][]StagedFile, messages []string) error {
+	l := logger.GetLogger()
+	if len(groups) != len(messages) {
+		return fmt.Errorf("expected one message for each of the %d groups, got %d", len(groups), len(messages))
+	}
+	if err := exec.Command(gitString, "reset", "-q").Run(); err != nil {
+		return fmt.Errorf("unstaging the changes: %w", err)
+	}
+	for i, group := range groups {
+		if err := commitGroup(group, messages[i]); err != nil {
+			var remaining []StagedFile
+			for _, g := range groups[i:] {
+				remaining = append(remaining, g...)
+			}
+			if restoreErr := exec.Command(gitString, "reset", "-q").Run(); restoreErr != nil {
+				l.Error("Failed to reset the index", "error", restoreErr)
+			} else if restoreErr := applyCached(remaining); restoreErr != nil {
+				l.Error("Failed to stage the uncommitted changes again", "error", restoreErr)
+			}
+			return err
+		}
+		l.Debug("Committed group", "files", len(group), "message", messages[i])
+	}
+	return nil
+}
+
+// commitGroup stages the patches of the group and commits them with the message.
+func commitGroup(group []StagedFile, message string) error {
+	// Start from an empty index, so a group that failed before does not leak into this commit
+	if err := exec.Command(gitString, "reset", "-q").Run(); err != nil {
+		return err
+	}
+	if err := applyCached(group); err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command(gitString, "commit", "-q", "-m", message)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("committing %s: %w: %s", message, err, stderr.String())
+	}
+	return nil
+}
+
+// applyCached stages the patches of the files.
+func applyCached(files []StagedFile) error {
+	if len(files) == 0 {
+		return nil
+	}
+	var patch strings.Builder
+	for _, file := range files {
+		patch.WriteString(file.Patch)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command(gitString, "apply", "--cached", "--binary", "-")
+	cmd.Stdin = strings.NewReader(patch.String())
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("staging the patches: %w: %s", err, stderr.String())
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package git_test
+
+import (
+	"strings"
+	"testing"
+
+	"gic/internal/git"
+)
+
+func TestGetStagedFiles(t *testing.T) {
+	newTestRepo(t)
+	writeFile(t, "a.go", "package a\n")
+	writeFile(t, "dir/b c.go", "package b\n")
+	// git quotes these paths in the diff headers
+	writeFile(t, "café.go", "package cafe\n")
+	writeFile(t, `say "hi".go`, "package hi\n")
+	writeFile(t, "README.md", "# changed\n")
+	runGit(t, "add", "-A")
+
+	files, err := git.GetStagedFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+		if !strings.HasPrefix(file.Patch, "diff --git ") {
+			t.Errorf("expected the patch of %s to start with its header, got %q", file.Path, file.Patch)
+		}
+	}
+	if got := strings.Join(paths, "|"); got != `README.md|a.go|café.go|dir/b c.go|say "hi".go` {
+		t.Errorf("unexpected files %q", got)
+	}
+}
+
+func TestCommitGroups(t *testing.T) {
+	newTestRepo(t)
+	writeFile(t, "a.go", "package a\n")
+	writeFile(t, "b.go", "package b\n")
+	writeFile(t, "README.md", "# changed\n")
+	runGit(t, "add", "-A")
+	writeFile(t, "unstaged.go", "package unstaged\n")
+
+	files, err := git.GetStagedFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	byPath := map[string]git.StagedFile{}
+	for _, file := range files {
+		byPath[file.Path] = file
+	}
+	groups := [][]git.StagedFile{{byPath["a.go"], byPath["b.go"]}, {byPath["README.md"]}}
+	if err := git.CommitGroups(groups, []string{"feat: add a and b", "docs: update readme"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := runGit(t, "log", "--format=%s", "-2"); got != "docs: update readme\nfeat: add a and b" {
+		t.Errorf("unexpected commits %q", got)
+	}
+	if got := runGit(t, "show", "--name-only", "--format=", "HEAD~1"); got != "a.go\nb.go" {
+		t.Errorf("unexpected files in the first commit %q", got)
+	}
+	if got := runGit(t, "status", "--porcelain"); got != "?? unstaged.go" {
+		t.Errorf("expected only the unstaged file to be left, got %q", got)
+	}
+}
+
+func TestCommitGroupsRestoresTheIndexOnFailure(t *testing.T) {
+	newTestRepo(t)
+	writeFile(t, "a.go", "package a\n")
+	writeFile(t, "b.go", "package b\n")
+	runGit(t, "add", "-A")
+
+	files, err := git.GetStagedFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// An empty message makes git commit fail for the second group
+	if err := git.CommitGroups([][]git.StagedFile{{files[0]}, {files[1]}}, []string{"feat: add a", ""}); err == nil {
+		t.Fatal("expected the second commit to fail")
+	}
+	if got := runGit(t, "diff", "--cached", "--name-only"); got != "b.go" {
+		t.Errorf("expected b.go to be staged again, got %q", got)
+	}
+}
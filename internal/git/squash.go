@@ -0,0 +1,96 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"gic/internal/logger"
+)
+
+// Squash holds the commits that squashing the current branch onto a base would combine.
+type Squash struct {
+	// Base is the merge base of HEAD and the base the commits are squashed onto.
+	Base string
+	// Messages are the full messages of the commits, oldest first.
+	Messages []string
+	// Diff is the combined diff of the commits.
+	Diff string
+}
+
+// GetSquash returns the messages and the combined diff of the commits between base and HEAD.
+func GetSquash(base string) (Squash, error) {
+	mergeBase, err := MergeBase("HEAD", base)
+	if err != nil {
+		return Squash{}, err
+	}
+	messages, err := CommitMessages(mergeBase + "..HEAD")
+	if err != nil {
+		return Squash{}, err
+	}
+	if len(messages) == 0 {
+		return Squash{}, fmt.Errorf("no commits to squash between %s and HEAD", base)
+	}
+	diff, err := exec.Command(gitString, "diff", mergeBase, "HEAD").Output()
+	if err != nil {
+		return Squash{}, err
+	}
+	return Squash{Base: mergeBase, Messages: messages, Diff: string(diff)}, nil
+}
+
+// RevList returns the commits in the revision range, oldest first.
+func RevList(revRange string) ([]string, error) {
+	out, err := exec.Command(gitString, "rev-list", "--reverse", revRange).Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing the commits in %s: %w", revRange, err)
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// CommitMessages returns the full messages of the commits in the revision range, oldest first.
+func CommitMessages(revRange string) ([]string, error) {
+	commits, err := RevList(revRange)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		out, err := exec.Command(gitString, "log", "-1", "--format=%B", commit).Output()
+		if err != nil {
+			return nil, fmt.Errorf("reading the message of %s: %w", commit, err)
+		}
+		messages = append(messages, strings.TrimSpace(string(out)))
+	}
+	return messages, nil
+}
+
+// SquashCommits replaces the commits after base with a single commit with the message.
+// It refuses to run when changes are staged, so they do not end up in the squashed commit.
+func SquashCommits(base, message string) error {
+	l := logger.GetLogger()
+	if err := exec.Command(gitString, "diff", "--cached", "--quiet").Run(); err != nil {
+		return fmt.Errorf("there are staged changes, commit or unstage them before squashing")
+	}
+	head, err := exec.Command(gitString, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return err
+	}
+
+	l.Debug("Squashing commits onto " + base)
+	if err := exec.Command(gitString, "reset", "-q", "--soft", base).Run(); err != nil {
+		return fmt.Errorf("resetting to %s: %w", base, err)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command(gitString, "commit", "-m", message)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		l.Error("Failed to commit the squashed changes, restoring HEAD", "error", err, "stderr", stderr.String())
+		oldHead := strings.TrimSpace(string(head))
+		if resetErr := exec.Command(gitString, "reset", "-q", "--soft", oldHead).Run(); resetErr != nil {
+			return fmt.Errorf("committing the squashed changes: %w, and restoring HEAD: %v", err, resetErr)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package git_test
+
+import (
+	"strings"
+	"testing"
+
+	"gic/internal/git"
+)
+
+func TestGetSquash(t *testing.T) {
+	newTestRepo(t)
+	runGit(t, "checkout", "-q", "-b", "feature")
+	commitFile(t, "a.go", "package a\n", "wip")
+	commitFile(t, "b.go", "package b\n", "fix b\n\nforgot the file")
+
+	squash, err := git.GetSquash("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if squash.Base != runGit(t, "rev-parse", "main") {
+		t.Errorf("expected base %s, got %s", runGit(t, "rev-parse", "main"), squash.Base)
+	}
+	if strings.Join(squash.Messages, "|") != "wip|fix b\n\nforgot the file" {
+		t.Errorf("unexpected messages %q", squash.Messages)
+	}
+	if !strings.Contains(squash.Diff, "a.go") || !strings.Contains(squash.Diff, "b.go") {
+		t.Errorf("expected the combined diff, got %q", squash.Diff)
+	}
+
+	if _, err := git.GetSquash("HEAD"); err == nil {
+		t.Error("expected an error when there is nothing to squash")
+	}
+}
+
+func TestSquashCommits(t *testing.T) {
+	newTestRepo(t)
+	base := runGit(t, "rev-parse", "HEAD")
+	commitFile(t, "a.go", "package a\n", "wip")
+	commitFile(t, "b.go", "package b\n", "wip again")
+
+	if err := git.SquashCommits(base, "feat: add a and b"); err != nil {
+		t.Fatal(err)
+	}
+	if got := runGit(t, "rev-list", "--count", base+"..HEAD"); got != "1" {
+		t.Errorf("expected one commit after the base, got %s", got)
+	}
+	if got := runGit(t, "log", "-1", "--format=%s"); got != "feat: add a and b" {
+		t.Errorf("unexpected message %q", got)
+	}
+	if got := runGit(t, "ls-files"); !strings.Contains(got, "a.go") || !strings.Contains(got, "b.go") {
+		t.Errorf("expected both files in the squashed commit, got %q", got)
+	}
+}
+
+func TestSquashCommitsRefusesStagedChanges(t *testing.T) {
+	newTestRepo(t)
+	base := runGit(t, "rev-parse", "HEAD")
+	commitFile(t, "a.go", "package a\n", "wip")
+	writeFile(t, "c.go", "package c\n")
+	runGit(t, "add", "c.go")
+
+	if err := git.SquashCommits(base, "feat: add a"); err == nil {
+		t.Fatal("expected an error with staged changes")
+	}
+	if got := runGit(t, "rev-list", "--count", base+"..HEAD"); got != "1" {
+		t.Errorf("expected HEAD to be left alone, got %s commits", got)
+	}
+}
@@ -0,0 +1,126 @@
+package git_test
+
+import (
+	"strings"
+	"testing"
+
+	"gic/internal/config"
+	"gic/internal/git"
+)
+
+// newWorkingTree leaves a staged change, an unstaged change and an untracked file in a new test repository.
+func newWorkingTree(t *testing.T) {
+	t.Helper()
+	newTestRepo(t)
+	commitFile(t, "tracked.go", "package tracked\n", "feat: add tracked")
+	writeFile(t, "staged.go", "package staged\n")
+	runGit(t, "add", "staged.go")
+	writeFile(t, "tracked.go", "package tracked\n\nfunc F() {}\n")
+	writeFile(t, "untracked.go", "package untracked\n")
+}
+
+func TestWorkingTreeDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		want    []string
+		notWant []string
+	}{
+		{name: "staged", want: []string{"staged.go"}, notWant: []string{"tracked.go", "untracked.go"}},
+		{
+			name:    "all",
+			cfg:     config.Config{All: true},
+			want:    []string{"staged.go", "tracked.go"},
+			notWant: []string{"untracked.go"},
+		},
+		{
+			name: "include untracked",
+			cfg:  config.Config{IncludeUntracked: true},
+			want: []string{"staged.go", "tracked.go", "untracked.go", "+package untracked"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newWorkingTree(t)
+			diff, err := git.GetGitDiff(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(diff, want) {
+					t.Errorf("expected the diff to contain %s, got %q", want, diff)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(diff, notWant) {
+					t.Errorf("expected the diff not to contain %s, got %q", notWant, diff)
+				}
+			}
+			if got := runGit(t, "diff", "--cached", "--name-only"); got != "staged.go" {
+				t.Errorf("expected the index to be left alone, got %q", got)
+			}
+		})
+	}
+}
+
+func TestWorkingTreeDiffOnUnbornBranch(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want []string
+	}{
+		{name: "all", cfg: config.Config{All: true}, want: []string{"README.md", "staged.go"}},
+		{
+			name: "include untracked",
+			cfg:  config.Config{IncludeUntracked: true},
+			want: []string{"README.md", "staged.go", "untracked.go"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestRepo(t)
+			// the index keeps README.md, but the new branch has no commit yet
+			runGit(t, "checkout", "-q", "--orphan", "fresh")
+			writeFile(t, "staged.go", "package staged\n")
+			runGit(t, "add", "staged.go")
+			writeFile(t, "untracked.go", "package untracked\n")
+
+			diff, err := git.GetGitDiff(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(diff, want) {
+					t.Errorf("expected the diff to contain %s, got %q", want, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCommitStagesTheWorkingTree(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want string
+		left string
+	}{
+		{name: "all", cfg: config.Config{All: true}, want: "staged.go\ntracked.go", left: "?? untracked.go"},
+		{name: "include untracked", cfg: config.Config{IncludeUntracked: true}, want: "staged.go\ntracked.go\nuntracked.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newWorkingTree(t)
+			tt.cfg.ShouldCommit = true
+			if err := git.Commit("feat: commit everything", tt.cfg); err != nil {
+				t.Fatal(err)
+			}
+			if got := runGit(t, "show", "--name-only", "--format=", "HEAD"); got != tt.want {
+				t.Errorf("expected %q in the commit, got %q", tt.want, got)
+			}
+			if got := runGit(t, "status", "--porcelain"); got != tt.left {
+				t.Errorf("expected %q to be left, got %q", tt.left, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"gic/internal/config"
+)
+
+const anthropicVersion = "2023-06-01"
+
+// anthropicProvider generates messages with the Anthropic Messages API.
+type anthropicProvider struct{}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	MaxTokens int                `json:"max_tokens"`
+	System    string             `json:"system,omitempty"`
+	Messages  []anthropicMessage `json:"messages"`
+	Stream    bool               `json:"stream,omitempty"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type anthropicStreamEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		Usage anthropicUsage `json:"usage"`
+	} `json:"message"`
+	Delta struct {
+		Type       string `json:"type"`
+		Text       string `json:"text"`
+		StopReason string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage anthropicUsage `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string         `json:"stop_reason"`
+	Usage      anthropicUsage `json:"usage"`
+}
+
+// anthropicFinishReasons maps the stop reasons of the Messages API to the normalised finish reasons.
+var anthropicFinishReasons = map[string]string{
+	"end_turn":      FinishStop,
+	"stop_sequence": FinishStop,
+	"max_tokens":    FinishLength,
+	"refusal":       FinishContentFilter,
+}
+
+func init() {
+	Register(anthropicProvider{})
+}
+
+// Name returns the name of the provider.
+func (anthropicProvider) Name() string {
+	return "anthropic"
+}
+
+// Model returns the configured Anthropic model.
+func (anthropicProvider) Model(cfg config.Config) string {
+	return cfg.ConnectionConfig.AnthropicModel
+}
+
+// Validate checks the Anthropic connection config.
+func (anthropicProvider) Validate(cfg config.Config) error {
+	if cfg.ConnectionConfig.AnthropicAPIKey == emptyString {
+		return fmt.Errorf("ANTHROPIC_API_KEY environment variable not set")
+	}
+	if _, err := parseBaseURL(cfg.ConnectionConfig.AnthropicBaseURL); err != nil {
+		return fmt.Errorf("ANTHROPIC_BASE_URL is not a valid url: %w", err)
+	}
+	if cfg.ConnectionConfig.AnthropicModel == emptyString {
+		return fmt.Errorf("ANTHROPIC_MODEL environment variable not set")
+	}
+	return nil
+}
+
+// Generate generates a message using the Anthropic Messages API.
+func (anthropicProvider) Generate(ctx context.Context, cfg config.Config, messages []Message) (Response, error) {
+	connCfg := cfg.ConnectionConfig
+	base, err := parseBaseURL(connCfg.AnthropicBaseURL)
+	if err != nil {
+		return Response{}, err
+	}
+	var resp anthropicResponse
+	err = postJSON(ctx, newAnthropicClient(cfg), base.JoinPath("v1", "messages").String(),
+		newAnthropicRequest(cfg, messages), &resp)
+	if err != nil {
+		return Response{}, err
+	}
+
+	var text strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	return Response{
+		Text:         text.String(),
+		Usage:        Usage{InputTokens: resp.Usage.InputTokens, OutputTokens: resp.Usage.OutputTokens},
+		FinishReason: anthropicFinishReason(resp.StopReason),
+	}, nil
+}
+
+// Stream streams a message from the Anthropic Messages API.
+func (anthropicProvider) Stream(
+	ctx context.Context,
+	cfg config.Config,
+	messages []Message,
+	onToken func(string),
+) (Response, error) {
+	base, err := parseBaseURL(cfg.ConnectionConfig.AnthropicBaseURL)
+	if err != nil {
+		return Response{}, err
+	}
+	req := newAnthropicRequest(cfg, messages)
+	req.Stream = true
+
+	var result Response
+	var text strings.Builder
+	err = postStream(ctx, newAnthropicClient(cfg), base.JoinPath("v1", "messages").String(), req,
+		func(data []byte) error {
+			var event anthropicStreamEvent
+			if err := json.Unmarshal(data, &event); err != nil {
+				return err
+			}
+			switch event.Type {
+			case "error":
+				return fmt.Errorf("anthropic stream error: %s: %s", event.Error.Type, event.Error.Message)
+			case "message_start":
+				result.Usage.InputTokens = event.Message.Usage.InputTokens
+			case "content_block_delta":
+				if event.Delta.Type == "text_delta" {
+					onToken(event.Delta.Text)
+					text.WriteString(event.Delta.Text)
+				}
+			case "message_delta":
+				result.FinishReason = anthropicFinishReason(event.Delta.StopReason)
+				result.Usage.OutputTokens = event.Usage.OutputTokens
+			}
+			return nil
+		})
+	if err != nil {
+		return Response{}, err
+	}
+	result.Text = text.String()
+	return result, nil
+}
+
+// anthropicFinishReason returns the normalised finish reason for a stop reason of the Messages API.
+func anthropicFinishReason(stopReason string) string {
+	if reason, ok := anthropicFinishReasons[stopReason]; ok {
+		return reason
+	}
+	return stopReason
+}
+
+func newAnthropicClient(cfg config.Config) *http.Client {
+	return newHTTPClient(cfg, baseTransport, map[string]string{
+		"x-api-key":         cfg.ConnectionConfig.AnthropicAPIKey,
+		"anthropic-version": anthropicVersion,
+	})
+}
+
+// newAnthropicRequest moves the system message to the top level system field, as the Messages API expects.
+func newAnthropicRequest(cfg config.Config, messages []Message) anthropicRequest {
+	req := anthropicRequest{
+		Model:     cfg.ConnectionConfig.AnthropicModel,
+		MaxTokens: cfg.ConnectionConfig.AnthropicMaxTokens,
+	}
+	for _, m := range messages {
+		if m.Role == roleSystem {
+			req.System = m.Content
+			continue
+		}
+		req.Messages = append(req.Messages, anthropicMessage{Role: m.Role, Content: m.Content})
+	}
+	return req
+}
@@ -0,0 +1,96 @@
+package llm_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gic/internal/config"
+	"gic/internal/llm"
+)
+
+type anthropicRequest struct {
+	Model     string        `json:"model"`
+	MaxTokens int           `json:"max_tokens"`
+	System    string        `json:"system"`
+	Messages  []chatMessage `json:"messages"`
+}
+
+const anthropicResponse = `{
+	"id": "msg_1",
+	"type": "message",
+	"role": "assistant",
+	"content": [{"type": "text", "text": "feat(llm): add anthropic provider"}],
+	"stop_reason": "end_turn"
+}`
+
+func newAnthropicConfig(base string) config.Config {
+	var cfg config.Config
+	cfg.LLMInstructions = testInstructions
+	cfg.ConnectionConfig.ServiceProvider = "anthropic"
+	cfg.ConnectionConfig.AnthropicAPIKey = "sk-ant-test"
+	cfg.ConnectionConfig.AnthropicBaseURL = base
+	cfg.ConnectionConfig.AnthropicModel = "claude-test"
+	cfg.ConnectionConfig.AnthropicMaxTokens = 512
+	return cfg
+}
+
+func TestAnthropicMessagesAPI(t *testing.T) {
+	var got *http.Request
+	var body anthropicRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request body: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(anthropicResponse))
+	}))
+	defer server.Close()
+
+	result, err := llm.GenerateCommitMessage(newAnthropicConfig(server.URL), testDiff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Message != "feat(llm): add anthropic provider" {
+		t.Fatalf("unexpected message: %q", result.Message)
+	}
+	if got.URL.Path != "/v1/messages" {
+		t.Errorf("expected path /v1/messages, got %s", got.URL.Path)
+	}
+	if key := got.Header.Get("x-api-key"); key != "sk-ant-test" {
+		t.Errorf("expected x-api-key header, got %q", key)
+	}
+	if version := got.Header.Get("anthropic-version"); version == "" {
+		t.Error("expected anthropic-version header")
+	}
+	if body.Model != "claude-test" || body.MaxTokens != 512 {
+		t.Errorf("unexpected model settings: %+v", body)
+	}
+	if body.System != testInstructions {
+		t.Errorf("expected system prompt %q, got %q", testInstructions, body.System)
+	}
+	want := chatMessage{Role: "user", Content: "git commit diff: " + testDiff}
+	if len(body.Messages) != 1 || body.Messages[0] != want {
+		t.Errorf("expected messages [%+v], got %+v", want, body.Messages)
+	}
+}
+
+func TestAnthropicErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`))
+	}))
+	defer server.Close()
+
+	_, err := llm.GenerateCommitMessage(newAnthropicConfig(server.URL), testDiff)
+	var statusErr *llm.StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("expected a StatusError, got %v", err)
+	}
+	if statusErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status 401, got %d", statusErr.StatusCode)
+	}
+}
@@ -0,0 +1,239 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+
+	"gic/internal/config"
+	"gic/internal/logger"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+const (
+	azureAPIKey  = "api_key"
+	azureAzureAD = "azure_ad"
+)
+
+// azureProvider generates messages with an Azure OpenAI deployment.
+type azureProvider struct{}
+
+func init() {
+	Register(azureProvider{})
+}
+
+// Name returns the name of the provider.
+func (azureProvider) Name() string {
+	return "azure"
+}
+
+// Model returns the configured Azure OpenAI deployment.
+func (azureProvider) Model(cfg config.Config) string {
+	return cfg.ConnectionConfig.AzureOpenAIDeploymentName
+}
+
+// Validate checks the Azure OpenAI connection config.
+func (azureProvider) Validate(cfg config.Config) error {
+	connCfg := cfg.ConnectionConfig
+	if connCfg.AzureAuthenticationType == emptyString {
+		return fmt.Errorf("AZURE_AUTHENTICATION_TYPE environment variable not set")
+	}
+	if connCfg.AzureAuthenticationType != azureAPIKey && connCfg.AzureAuthenticationType != azureAzureAD {
+		return fmt.Errorf("AZURE_AUTHENTICATION_TYPE must be either 'api_key' or 'azure_ad'")
+	}
+	if connCfg.AzureAuthenticationType == azureAPIKey && connCfg.AzureOpenAIAPIKey == emptyString {
+		return fmt.Errorf("AZURE_OPENAI_API_KEY environment variable not set for api_key authentication type")
+	}
+	if connCfg.AzureOpenAIEndpoint == emptyString {
+		return fmt.Errorf("AZURE_OPENAI_ENDPOINT environment variable not set")
+	}
+	if connCfg.AzureOpenAIDeploymentName == emptyString {
+		return fmt.Errorf("AZURE_OPENAI_DEPLOYMENT_NAME environment variable not set")
+	}
+	return nil
+}
+
+// Generate generates a message using the Azure OpenAI service.
+func (azureProvider) Generate(ctx context.Context, cfg config.Config, messages []Message) (Response, error) {
+	client, err := newAzureClient(cfg)
+	if err != nil {
+		return Response{}, err
+	}
+
+	resp, err := client.GetChatCompletions(ctx, azopenai.ChatCompletionsOptions{
+		Messages:       azureMessages(messages),
+		DeploymentName: &(cfg.ConnectionConfig.AzureOpenAIDeploymentName),
+	}, nil)
+	if err != nil {
+		logger.GetLogger().Error("Azure chat completion failed", "error", err)
+		return Response{}, err
+	}
+
+	var result Response
+	for _, choice := range resp.Choices {
+		if choice.ContentFilterResults != nil {
+			if choice.ContentFilterResults.Error != nil {
+				return Response{}, fmt.Errorf("%w: %w", ErrContentFiltered, choice.ContentFilterResults.Error)
+			}
+		}
+		if choice.Message != nil && choice.Message.Content != nil {
+			result.Text = *choice.Message.Content
+		}
+		if choice.FinishReason != nil {
+			result.FinishReason = string(*choice.FinishReason)
+		}
+	}
+	result.Usage = azureUsage(resp.Usage)
+	return result, nil
+}
+
+// Stream streams a message from the Azure OpenAI service.
+func (azureProvider) Stream(
+	ctx context.Context,
+	cfg config.Config,
+	messages []Message,
+	onToken func(string),
+) (Response, error) {
+	client, err := newAzureClient(cfg)
+	if err != nil {
+		return Response{}, err
+	}
+
+	resp, err := client.GetChatCompletionsStream(ctx, azopenai.ChatCompletionsStreamOptions{
+		Messages:       azureMessages(messages),
+		DeploymentName: &(cfg.ConnectionConfig.AzureOpenAIDeploymentName),
+	}, nil)
+	if err != nil {
+		logger.GetLogger().Error("Azure chat completion stream failed", "error", err)
+		return Response{}, err
+	}
+	defer resp.ChatCompletionsStream.Close()
+
+	var result Response
+	var text strings.Builder
+	for {
+		chunk, err := resp.ChatCompletionsStream.Read()
+		if errors.Is(err, io.EOF) {
+			result.Text = text.String()
+			return result, nil
+		}
+		if err != nil {
+			return Response{}, err
+		}
+		if err := readAzureChoices(chunk.Choices, &result, &text, onToken); err != nil {
+			return Response{}, err
+		}
+		if chunk.Usage != nil {
+			result.Usage = azureUsage(chunk.Usage)
+		}
+	}
+}
+
+// readAzureChoices passes the content of the streamed choices to onToken and appends it to text.
+// It records the finish reason in the result, and fails when the content filter blocked the response.
+func readAzureChoices(
+	choices []azopenai.ChatChoice,
+	result *Response,
+	text *strings.Builder,
+	onToken func(string),
+) error {
+	for _, choice := range choices {
+		if choice.ContentFilterResults != nil && choice.ContentFilterResults.Error != nil {
+			return fmt.Errorf("%w: %w", ErrContentFiltered, choice.ContentFilterResults.Error)
+		}
+		if choice.Delta != nil && choice.Delta.Content != nil {
+			onToken(*choice.Delta.Content)
+			text.WriteString(*choice.Delta.Content)
+		}
+		if choice.FinishReason != nil {
+			result.FinishReason = string(*choice.FinishReason)
+		}
+	}
+	return nil
+}
+
+func azureUsage(usage *azopenai.CompletionsUsage) Usage {
+	if usage == nil {
+		return Usage{}
+	}
+	var u Usage
+	if usage.PromptTokens != nil {
+		u.InputTokens = int(*usage.PromptTokens)
+	}
+	if usage.CompletionTokens != nil {
+		u.OutputTokens = int(*usage.CompletionTokens)
+	}
+	return u
+}
+
+func newAzureClient(cfg config.Config) (*azopenai.Client, error) {
+	switch cfg.ConnectionConfig.AzureAuthenticationType {
+	case azureAPIKey:
+		keyCredential := azcore.NewKeyCredential(cfg.ConnectionConfig.AzureOpenAIAPIKey)
+		return azopenai.NewClientWithKeyCredential(
+			cfg.ConnectionConfig.AzureOpenAIEndpoint,
+			keyCredential,
+			azureClientOptions(cfg, isLoopback(cfg.ConnectionConfig.AzureOpenAIEndpoint)),
+		)
+	case azureAzureAD:
+		tokenCredential, err := azidentity.NewDefaultAzureCredential(nil)
+		if err != nil {
+			return nil, err
+		}
+		return azopenai.NewClient(cfg.ConnectionConfig.AzureOpenAIEndpoint, tokenCredential, azureClientOptions(cfg, false))
+	default:
+		return nil, fmt.Errorf(
+			"unsupported azure authentication type: %s",
+			cfg.ConnectionConfig.AzureAuthenticationType,
+		)
+	}
+}
+
+// azureClientOptions sends requests through the shared transport. Retries are handled there,
+// so the retry policy of the SDK is disabled.
+func azureClientOptions(cfg config.Config, allowHTTP bool) *azopenai.ClientOptions {
+	return &azopenai.ClientOptions{ClientOptions: azcore.ClientOptions{
+		InsecureAllowCredentialWithHTTP: allowHTTP,
+		Retry:                           policy.RetryOptions{MaxRetries: -1},
+		Transport:                       newHTTPClient(cfg, baseTransport, nil),
+	}}
+}
+
+// isLoopback reports whether the endpoint points at the local machine, e.g. an emulator or a local proxy.
+// Only then the api key is allowed to be sent over plain http.
+func isLoopback(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return false
+	}
+	if u.Hostname() == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(u.Hostname())
+	return ip != nil && ip.IsLoopback()
+}
+
+func azureMessages(messages []Message) []azopenai.ChatRequestMessageClassification {
+	azMessages := make([]azopenai.ChatRequestMessageClassification, 0, len(messages))
+	for _, m := range messages {
+		switch m.Role {
+		case roleSystem:
+			azMessages = append(azMessages, &azopenai.ChatRequestSystemMessage{
+				Content: azopenai.NewChatRequestSystemMessageContent(m.Content),
+			})
+		default:
+			azMessages = append(azMessages, &azopenai.ChatRequestUserMessage{
+				Content: azopenai.NewChatRequestUserMessageContent(m.Content),
+			})
+		}
+	}
+	return azMessages
+}
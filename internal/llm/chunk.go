@@ -0,0 +1,161 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"gic/internal/config"
+)
+
+const (
+	fileHeaderPrefix        = "diff --git "
+	hunkHeaderPrefix        = "@@"
+	defaultChunkConcurrency = 4
+)
+
+// splitDiff splits a diff into chunks of at most maxTokens. Files are kept together when they fit,
+// larger files are split per hunk and every hunk chunk repeats the file header so it can be read on its own.
+func splitDiff(diff string, maxTokens int) []string {
+	return pack(splitSections(diff, fileHeaderPrefix), emptyString, maxTokens, splitFile)
+}
+
+// splitFile splits the diff of a single file into chunks of hunks.
+func splitFile(file string, maxTokens int) []string {
+	sections := splitSections(file, hunkHeaderPrefix)
+	if len(sections) < 2 || strings.HasPrefix(sections[0], hunkHeaderPrefix) {
+		return splitLines(file, maxTokens)
+	}
+	return pack(sections[1:], sections[0], maxTokens, splitLines)
+}
+
+// splitSections splits text into sections that each start at a line beginning with prefix.
+// Lines before the first match form their own section.
+func splitSections(text, prefix string) []string {
+	var sections []string
+	var current strings.Builder
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if strings.HasPrefix(line, prefix) && current.Len() > 0 {
+			sections = append(sections, current.String())
+			current.Reset()
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 {
+		sections = append(sections, current.String())
+	}
+	return sections
+}
+
+// pack greedily groups sections into chunks of at most maxTokens, starting every chunk with header.
+// Sections that do not fit on their own are broken up with split.
+func pack(sections []string, header string, maxTokens int, split func(string, int) []string) []string {
+	budget := max(maxTokens-estimateTokens(header), minInputTokens)
+	var chunks []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			chunks = append(chunks, header+current.String())
+			current.Reset()
+		}
+	}
+	for _, section := range sections {
+		if estimateTokens(section) > budget {
+			flush()
+			for _, part := range split(section, budget) {
+				chunks = append(chunks, header+part)
+			}
+			continue
+		}
+		if estimateTokens(current.String()+section) > budget {
+			flush()
+		}
+		current.WriteString(section)
+	}
+	flush()
+	return chunks
+}
+
+// splitLines cuts text at line boundaries into parts of at most maxTokens.
+// Lines that are too long on their own are cut as well.
+func splitLines(text string, maxTokens int) []string {
+	maxChars := max(maxTokens, minInputTokens) * charsPerToken
+	var parts []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			parts = append(parts, current.String())
+			current.Reset()
+		}
+	}
+	for _, line := range strings.SplitAfter(text, "\n") {
+		for len(line) > maxChars {
+			flush()
+			parts = append(parts, line[:maxChars])
+			line = line[maxChars:]
+		}
+		if current.Len()+len(line) > maxChars {
+			flush()
+		}
+		current.WriteString(line)
+	}
+	flush()
+	return parts
+}
+
+// summariseChunks asks the provider to summarise every chunk, running up to chunk_concurrency requests at once.
+// The summaries are returned in the order of the chunks, with the usage of all the requests.
+func summariseChunks(ctx context.Context, p Provider, cfg config.Config, chunks []string) ([]string, Usage, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	concurrency := cfg.ChunkConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultChunkConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+	responses := make([]Response, len(chunks))
+	errs := make([]error, len(chunks))
+
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			responses[i], errs[i] = complete(ctx, p, cfg, buildChunkMessages(chunk, i+1, len(chunks)), nil)
+			if errs[i] != nil {
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := firstError(errs); err != nil {
+		return nil, Usage{}, err
+	}
+	summaries := make([]string, len(responses))
+	var usage Usage
+	for i, resp := range responses {
+		summaries[i] = resp.Text
+		usage = usage.add(resp.Usage)
+	}
+	return summaries, usage, nil
+}
+
+// firstError returns the error that made the other requests get cancelled, if there is one.
+func firstError(errs []error) error {
+	var cancelled error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, context.Canceled) {
+			return err
+		}
+		cancelled = err
+	}
+	return cancelled
+}
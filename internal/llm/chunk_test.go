@@ -0,0 +1,86 @@
+package llm_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"gic/internal/config"
+	"gic/internal/llm"
+)
+
+// bigDiff returns a diff of files with two hunks each, every hunk being roughly hunkLines lines long.
+func bigDiff(files, hunkLines int) string {
+	var b strings.Builder
+	for f := 0; f < files; f++ {
+		fmt.Fprintf(&b, "diff --git a/file%d.go b/file%d.go\n--- a/file%d.go\n+++ b/file%d.go\n", f, f, f, f)
+		for h := 0; h < 2; h++ {
+			fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", h*100+1, hunkLines, h*100+1, hunkLines)
+			for i := 0; i < hunkLines; i++ {
+				fmt.Fprintf(&b, "+\tvalue%d := compute(%d, %d)\n", i, f, h)
+			}
+		}
+	}
+	return b.String()
+}
+
+func TestLargeDiffIsSummarisedInChunks(t *testing.T) {
+	var mu sync.Mutex
+	var chunkRequests []string
+	var finalRequests []chatMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []chatMessage `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request body: %v", err)
+		}
+		mu.Lock()
+		content := "summary"
+		if body.Messages[0].Content == testInstructions {
+			finalRequests = append(finalRequests, body.Messages[1])
+			content = "feat: large change"
+		} else {
+			chunkRequests = append(chunkRequests, body.Messages[1].Content)
+		}
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"model":"phi3","message":{"role":"assistant","content":%q},"done":true}`, content)
+	}))
+	defer server.Close()
+
+	var cfg config.Config
+	cfg.LLMInstructions = testInstructions
+	cfg.MaxInputTokens = 1600
+	cfg.ChunkConcurrency = 2
+	cfg.ConnectionConfig.ServiceProvider = "ollama"
+	cfg.ConnectionConfig.OllamaAPIKey = "key"
+	cfg.ConnectionConfig.OllamaAPIBase = server.URL
+	cfg.ConnectionConfig.OllamaDeploymentName = "phi3"
+
+	result, err := llm.GenerateCommitMessage(cfg, bigDiff(3, 40))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Message != "feat: large change" {
+		t.Fatalf("unexpected message: %q", result.Message)
+	}
+	if len(chunkRequests) < 3 {
+		t.Fatalf("expected the diff to be split in at least 3 chunks, got %d", len(chunkRequests))
+	}
+	for _, chunk := range chunkRequests {
+		if !strings.Contains(chunk, "of the git diff: diff --git a/file") {
+			t.Errorf("expected every chunk to start with its file header, got %q", chunk[:80])
+		}
+	}
+	if len(finalRequests) != 1 {
+		t.Fatalf("expected one final request, got %d", len(finalRequests))
+	}
+	if !strings.HasPrefix(finalRequests[0].Content, "summaries of the parts of the git commit diff: ") {
+		t.Errorf("expected the final request to contain the summaries, got %q", finalRequests[0].Content)
+	}
+}
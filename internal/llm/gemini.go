@@ -0,0 +1,205 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"gic/internal/config"
+)
+
+// geminiProvider generates messages with the Gemini generateContent API.
+// The same request shape is served by Vertex AI, so GEMINI_API_BASE can point at either.
+type geminiProvider struct{}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiRequest struct {
+	SystemInstruction *geminiContent  `json:"systemInstruction,omitempty"`
+	Contents          []geminiContent `json:"contents"`
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	PromptFeedback struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
+}
+
+// geminiFinishReasons maps the finish reasons of the Gemini API to the normalised finish reasons.
+var geminiFinishReasons = map[string]string{
+	"STOP":               FinishStop,
+	"MAX_TOKENS":         FinishLength,
+	"SAFETY":             FinishContentFilter,
+	"RECITATION":         FinishContentFilter,
+	"BLOCKLIST":          FinishContentFilter,
+	"PROHIBITED_CONTENT": FinishContentFilter,
+	"SPII":               FinishContentFilter,
+}
+
+func init() {
+	Register(geminiProvider{})
+}
+
+// Name returns the name of the provider.
+func (geminiProvider) Name() string {
+	return "gemini"
+}
+
+// Model returns the configured Gemini model.
+func (geminiProvider) Model(cfg config.Config) string {
+	return cfg.ConnectionConfig.GeminiModel
+}
+
+// Validate checks the Gemini connection config.
+func (geminiProvider) Validate(cfg config.Config) error {
+	if cfg.ConnectionConfig.GeminiAPIKey == emptyString {
+		return fmt.Errorf("GEMINI_API_KEY environment variable not set")
+	}
+	if _, err := parseBaseURL(cfg.ConnectionConfig.GeminiAPIBase); err != nil {
+		return fmt.Errorf("GEMINI_API_BASE is not a valid url: %w", err)
+	}
+	if cfg.ConnectionConfig.GeminiModel == emptyString {
+		return fmt.Errorf("GEMINI_MODEL environment variable not set")
+	}
+	return nil
+}
+
+// Generate generates a message using the Gemini API.
+func (geminiProvider) Generate(ctx context.Context, cfg config.Config, messages []Message) (Response, error) {
+	endpoint, err := geminiEndpoint(cfg, "generateContent")
+	if err != nil {
+		return Response{}, err
+	}
+
+	var resp geminiResponse
+	if err := postJSON(ctx, newGeminiClient(cfg), endpoint, newGeminiRequest(messages), &resp); err != nil {
+		return Response{}, err
+	}
+	if err := resp.blocked(); err != nil {
+		return Response{}, err
+	}
+	if len(resp.Candidates) == 0 {
+		return Response{}, fmt.Errorf("gemini returned no candidates")
+	}
+	return Response{Text: resp.text(), Usage: resp.usage(), FinishReason: resp.finishReason()}, nil
+}
+
+// Stream streams a message from the Gemini API.
+func (geminiProvider) Stream(
+	ctx context.Context,
+	cfg config.Config,
+	messages []Message,
+	onToken func(string),
+) (Response, error) {
+	endpoint, err := geminiEndpoint(cfg, "streamGenerateContent")
+	if err != nil {
+		return Response{}, err
+	}
+
+	var result Response
+	var text strings.Builder
+	err = postStream(ctx, newGeminiClient(cfg), endpoint+"?alt=sse", newGeminiRequest(messages),
+		func(data []byte) error {
+			var resp geminiResponse
+			if err := json.Unmarshal(data, &resp); err != nil {
+				return err
+			}
+			if err := resp.blocked(); err != nil {
+				return err
+			}
+			token := resp.text()
+			onToken(token)
+			text.WriteString(token)
+			if reason := resp.finishReason(); reason != emptyString {
+				result.FinishReason = reason
+			}
+			// Every chunk carries the usage so far
+			result.Usage = resp.usage()
+			return nil
+		})
+	if err != nil {
+		return Response{}, err
+	}
+	result.Text = text.String()
+	return result, nil
+}
+
+// blocked returns ErrContentFiltered when the prompt itself was blocked, in which case there are no candidates.
+func (r geminiResponse) blocked() error {
+	if r.PromptFeedback.BlockReason == emptyString {
+		return nil
+	}
+	return fmt.Errorf("%w: gemini blocked the prompt: %s", ErrContentFiltered, r.PromptFeedback.BlockReason)
+}
+
+// text returns the text of the first candidate.
+func (r geminiResponse) text() string {
+	if len(r.Candidates) == 0 {
+		return emptyString
+	}
+	var text strings.Builder
+	for _, part := range r.Candidates[responseMessage].Content.Parts {
+		text.WriteString(part.Text)
+	}
+	return text.String()
+}
+
+// finishReason returns the normalised finish reason of the first candidate.
+func (r geminiResponse) finishReason() string {
+	if len(r.Candidates) == 0 {
+		return emptyString
+	}
+	reason := r.Candidates[responseMessage].FinishReason
+	if normalised, ok := geminiFinishReasons[reason]; ok {
+		return normalised
+	}
+	return reason
+}
+
+func (r geminiResponse) usage() Usage {
+	return Usage{InputTokens: r.UsageMetadata.PromptTokenCount, OutputTokens: r.UsageMetadata.CandidatesTokenCount}
+}
+
+func geminiEndpoint(cfg config.Config, method string) (string, error) {
+	base, err := parseBaseURL(cfg.ConnectionConfig.GeminiAPIBase)
+	if err != nil {
+		return emptyString, err
+	}
+	return base.JoinPath("models", cfg.ConnectionConfig.GeminiModel+":"+method).String(), nil
+}
+
+func newGeminiClient(cfg config.Config) *http.Client {
+	return newHTTPClient(cfg, baseTransport, map[string]string{"x-goog-api-key": cfg.ConnectionConfig.GeminiAPIKey})
+}
+
+// newGeminiRequest sends the system message as systemInstruction and the rest as user contents.
+func newGeminiRequest(messages []Message) geminiRequest {
+	var req geminiRequest
+	for _, m := range messages {
+		content := geminiContent{Parts: []geminiPart{{Text: m.Content}}}
+		if m.Role == roleSystem {
+			req.SystemInstruction = &content
+			continue
+		}
+		content.Role = m.Role
+		req.Contents = append(req.Contents, content)
+	}
+	return req
+}
@@ -0,0 +1,68 @@
+package llm_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gic/internal/config"
+	"gic/internal/llm"
+)
+
+type geminiContent struct {
+	Role  string `json:"role"`
+	Parts []struct {
+		Text string `json:"text"`
+	} `json:"parts"`
+}
+
+func newGeminiConfig(base string) config.Config {
+	var cfg config.Config
+	cfg.LLMInstructions = testInstructions
+	cfg.ConnectionConfig.ServiceProvider = "gemini"
+	cfg.ConnectionConfig.GeminiAPIKey = "gemini-key"
+	cfg.ConnectionConfig.GeminiAPIBase = base
+	cfg.ConnectionConfig.GeminiModel = "gemini-test"
+	return cfg
+}
+
+func TestGeminiGenerateContent(t *testing.T) {
+	var got *http.Request
+	var body struct {
+		SystemInstruction geminiContent   `json:"systemInstruction"`
+		Contents          []geminiContent `json:"contents"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request body: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"feat: add gemini"}]},` +
+			`"finishReason":"STOP"}]}`))
+	}))
+	defer server.Close()
+
+	cfg := newGeminiConfig(server.URL + "/v1/publishers/google")
+	result, err := llm.GenerateCommitMessage(cfg, testDiff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Message != "feat: add gemini" {
+		t.Fatalf("unexpected message: %q", result.Message)
+	}
+	if got.URL.Path != "/v1/publishers/google/models/gemini-test:generateContent" {
+		t.Errorf("unexpected path %s", got.URL.Path)
+	}
+	if key := got.Header.Get("x-goog-api-key"); key != "gemini-key" {
+		t.Errorf("expected x-goog-api-key header, got %q", key)
+	}
+	if len(body.SystemInstruction.Parts) != 1 || body.SystemInstruction.Parts[0].Text != testInstructions {
+		t.Errorf("unexpected system instruction: %+v", body.SystemInstruction)
+	}
+	if len(body.Contents) != 1 || body.Contents[0].Role != "user" ||
+		body.Contents[0].Parts[0].Text != "git commit diff: "+testDiff {
+		t.Errorf("unexpected contents: %+v", body.Contents)
+	}
+}
@@ -0,0 +1,163 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"gic/internal/config"
+)
+
+// maxErrorBody caps how much of an error response body is kept in an error message.
+const maxErrorBody = 4096
+
+const (
+	sseBufferSize  = 64 * 1024
+	maxSSELineSize = 1024 * 1024
+)
+
+var baseTransport http.RoundTripper = http.DefaultTransport
+
+// SetHTTPTransport replaces the transport every provider uses to reach its service.
+// It allows routing requests through custom gateways, proxies or test servers.
+// Passing nil restores http.DefaultTransport.
+func SetHTTPTransport(rt http.RoundTripper) {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	baseTransport = rt
+}
+
+// headerTransport sets a fixed set of headers on every request before handing it to the next transport.
+type headerTransport struct {
+	next    http.RoundTripper
+	headers map[string]string
+}
+
+// RoundTrip adds the headers to a clone of the request and sends it.
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// newHTTPClient returns a client sending requests through rt and adding the given headers to every request.
+// Requests are retried and limited in time according to the timeout and retry settings of the config.
+func newHTTPClient(cfg config.Config, rt http.RoundTripper, headers map[string]string) *http.Client {
+	rt = newRetryTransport(cfg, rt)
+	if len(headers) > 0 {
+		rt = &headerTransport{next: rt, headers: headers}
+	}
+	return &http.Client{Transport: rt}
+}
+
+// withCACert returns a copy of the shared transport trusting the certificates in caFile,
+// for gateways signed by an internal certificate authority.
+func withCACert(caFile string) (http.RoundTripper, error) {
+	if caFile == emptyString {
+		return baseTransport, nil
+	}
+	pem, err := os.ReadFile(caFile) // #nosec G304 -- the path comes from the user's own configuration
+	if err != nil {
+		return nil, err
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+	base, ok := baseTransport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("a CA certificate can only be used with an *http.Transport")
+	}
+	transport := base.Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	return transport, nil
+}
+
+// StatusError is returned when a provider answers with a non 2xx status code.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+// Error returns the status code and response body of the failed request.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// postJSON sends body as JSON to url and decodes the JSON response into out.
+func postJSON(ctx context.Context, client *http.Client, url string, body, out any) error {
+	resp, err := post(ctx, client, url, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// postStream sends body as JSON to url and calls onEvent with the data of every server-sent event of the response.
+func postStream(ctx context.Context, client *http.Client, url string, body any, onEvent func(data []byte) error) error {
+	resp, err := post(ctx, client, url, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, sseBufferSize), maxSSELineSize)
+	for scanner.Scan() {
+		data, found := strings.CutPrefix(scanner.Text(), "data:")
+		if !found {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == emptyString || data == "[DONE]" {
+			continue
+		}
+		if err := onEvent([]byte(data)); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// post sends body as JSON to url and returns the response when it has a 2xx status code.
+func post(ctx context.Context, client *http.Client, url string, body any) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		defer resp.Body.Close()
+		errBody, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		if err != nil {
+			// The status is still worth reporting without the body explaining it
+			errBody = []byte(http.StatusText(resp.StatusCode))
+		}
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(errBody))}
+	}
+	return resp, nil
+}
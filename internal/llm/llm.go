@@ -1,159 +1,159 @@
-// Package llm provides the logic for generating commit messages based on git diffs.
-package llm
-
-import (
-	"context"
-	"fmt"
-	"log"
-
-	"gic/internal/config"
-	"gic/internal/logger"
-
-	"github.com/Azure/azure-sdk-for-go/sdk/ai/azopenai"
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/openai/openai-go"
-	"github.com/openai/openai-go/option"
-
-	"github.com/ollama/ollama/api"
-)
-
-const emptyString = ""
-const responseMessage = 0
-
-// GenerateCommitMessage generates a commit message based on the provided configuration and diff.
-func GenerateCommitMessage(cfg config.Config, diff string) (string, error) {
-	l := logger.GetLogger()
-	l.Info("Generating commit message")
-	if diff == emptyString {
-		l.Info("No files staged for commit")
-		return "### NO STAGED CHAGES ###", nil
-	}
-
-	switch cfg.ConnectionConfig.ServiceProvider {
-	case "azure":
-		return GenerateCommitMessageAzure(cfg, diff)
-	case "openai":
-		return GenerateCommitMessageOpenAI(cfg, diff)
-	case "ollama":
-		return GenerateCommitMessageOllama(cfg, diff)
-	default:
-		return emptyString, fmt.Errorf("unsupported connection type: %s", cfg.ConnectionConfig.ServiceProvider)
-	}
-}
-
-// GenerateCommitMessageOllama generates a commit message using the Ollama service.
-func GenerateCommitMessageOllama(cfg config.Config, diff string) (string, error) {
-	client, err := api.ClientFromEnvironment()
-	if err != nil {
-		return emptyString, err
-	}
-
-	messages := []api.Message{
-		{
-			Role:    "system",
-			Content: cfg.LLMInstructions,
-		},
-		{
-			Role:    "user",
-			Content: "git commit diff: " + diff,
-		},
-	}
-
-	ctx := context.Background()
-	req := &api.ChatRequest{
-		Model:    cfg.ConnectionConfig.OllamaDeploymentName,
-		Messages: messages,
-		Stream:   func(b bool) *bool { return &b }(false),
-	}
-
-	var commitMessage string
-	respFunc := func(resp api.ChatResponse) error {
-		commitMessage = resp.Message.Content
-		return nil
-	}
-	err = client.Chat(ctx, req, respFunc)
-	if err != nil {
-		return emptyString, err
-	}
-	return commitMessage, nil
-}
-
-// GenerateCommitMessageAzure generates a commit message using the Azure service.
-func GenerateCommitMessageAzure(cfg config.Config, diff string) (string, error) {
-	var client *azopenai.Client
-	var err error
-
-	if cfg.ConnectionConfig.AzureAuthenticationType == "api_key" {
-		keyCredential := azcore.NewKeyCredential(cfg.ConnectionConfig.AzureOpenAIAPIKey)
-		client, err = azopenai.NewClientWithKeyCredential(cfg.ConnectionConfig.AzureOpenAIEndpoint, keyCredential, nil)
-	} else if cfg.ConnectionConfig.AzureAuthenticationType == "azure_ad" {
-		tokenCredential, tokenErr := azidentity.NewDefaultAzureCredential(nil)
-		if tokenErr != nil {
-			return emptyString, tokenErr
-		}
-		client, err = azopenai.NewClient(cfg.ConnectionConfig.AzureOpenAIEndpoint, tokenCredential, nil)
-	} else {
-		return emptyString, fmt.Errorf(
-			"unsupported azure authentication type: %s",
-			cfg.ConnectionConfig.AzureAuthenticationType,
-		)
-	}
-
-	if err != nil {
-		return emptyString, err
-	}
-
-	return getChatCompletions(cfg, client, diff)
-}
-
-// GenerateCommitMessageOpenAI generates a commit message using the OpenAI service.
-func GenerateCommitMessageOpenAI(cfg config.Config, diff string) (string, error) {
-	client := openai.NewClient(
-		option.WithAPIKey(cfg.ConnectionConfig.OpenAIAPIKey),
-	)
-	chatCompletion, err := client.Chat.Completions.New(context.TODO(), openai.ChatCompletionNewParams{
-		Messages: openai.F([]openai.ChatCompletionMessageParamUnion{
-			openai.UserMessage(diff),
-		}),
-		Model: openai.F(cfg.ConnectionConfig.OpenAIDeploymentName),
-	})
-	if err != nil {
-		return emptyString, err
-	}
-	return chatCompletion.Choices[responseMessage].Message.Content, nil
-}
-
-func getChatCompletions(cfg config.Config, client *azopenai.Client, diff string) (string, error) {
-	messages := []azopenai.ChatRequestMessageClassification{
-		&azopenai.ChatRequestSystemMessage{
-
-			Content: azopenai.NewChatRequestSystemMessageContent(cfg.LLMInstructions),
-		},
-		&azopenai.ChatRequestUserMessage{
-			Content: azopenai.NewChatRequestUserMessageContent("git commit diff: " + diff),
-		},
-	}
-
-	resp, err := client.GetChatCompletions(context.TODO(), azopenai.ChatCompletionsOptions{
-		Messages:       messages,
-		DeploymentName: &(cfg.ConnectionConfig.AzureOpenAIDeploymentName),
-	}, nil)
-
-	if err != nil {
-		log.Printf("ERROR: %s", err)
-		return emptyString, err
-	}
-
-	var messageContent string
-	for _, choice := range resp.Choices {
-		if choice.ContentFilterResults != nil {
-			if choice.ContentFilterResults.Error != nil {
-				return emptyString, choice.ContentFilterResults.Error
-			}
-		}
-		messageContent = *choice.Message.Content
-	}
-
-	return messageContent, nil
-}
+// Package llm provides the logic for generating commit messages based on git diffs.
+package llm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gic/internal/config"
+	"gic/internal/logger"
+)
+
+const emptyString = ""
+const responseMessage = 0
+const maxReduceRounds = 3
+
+// GenerateCommitMessage generates a commit message based on the provided configuration and diff.
+// It returns ErrNoChanges when the diff is empty.
+func GenerateCommitMessage(cfg config.Config, diff string) (Result, error) {
+	return StreamCommitMessage(cfg, diff, nil)
+}
+
+// StreamCommitMessage generates a commit message like GenerateCommitMessage and writes its text to w as it
+// arrives from the provider. Providers that can not stream write the whole message once it is generated.
+// A nil w disables streaming.
+func StreamCommitMessage(cfg config.Config, diff string, w io.Writer) (Result, error) {
+	l := logger.GetLogger()
+	l.Info("Generating commit message")
+	if strings.TrimSpace(diff) == emptyString {
+		l.Info("No files staged for commit")
+		return Result{}, ErrNoChanges
+	}
+	return run(cfg, commitPrompt(cfg, diff), w)
+}
+
+// GenerateSquashMessage generates one commit message for several commits that are squashed,
+// from their messages and their combined diff.
+func GenerateSquashMessage(cfg config.Config, messages []string, diff string) (Result, error) {
+	l := logger.GetLogger()
+	l.Info("Generating squash commit message")
+	if strings.TrimSpace(diff) == emptyString {
+		return Result{}, fmt.Errorf("%w: the commits being squashed have no changes", ErrNoChanges)
+	}
+	return run(cfg, squashPrompt(cfg, messages, diff), nil)
+}
+
+// GeneratePullRequest generates a Markdown pull request title and description from the commit subjects,
+// the diffstat and the diff of a branch, following pr_instructions.
+func GeneratePullRequest(cfg config.Config, commits []string, diffStat, diff string) (Result, error) {
+	l := logger.GetLogger()
+	l.Info("Generating pull request description")
+	if strings.TrimSpace(diff) == emptyString {
+		return Result{}, fmt.Errorf("%w between the base branch and HEAD", ErrNoChanges)
+	}
+	return run(cfg, pullRequestPrompt(cfg, commits, diffStat, diff), nil)
+}
+
+// SummarizeRange generates a message describing a revision range from its commit subjects, diffstat and diff,
+// following llm_instructions.
+func SummarizeRange(cfg config.Config, commits []string, diffStat, diff string) (Result, error) {
+	l := logger.GetLogger()
+	l.Info("Summarising revision range")
+	if strings.TrimSpace(diff) == emptyString {
+		return Result{}, fmt.Errorf("%w in the range", ErrNoChanges)
+	}
+	return run(cfg, rangePrompt(cfg, commits, diffStat, diff), nil)
+}
+
+// run generates the prompt with the configured provider.
+func run(cfg config.Config, pr prompt, w io.Writer) (Result, error) {
+	provider, err := GetProvider(cfg.ConnectionConfig.ServiceProvider)
+	if err != nil {
+		return Result{}, err
+	}
+	logger.GetLogger().Debug("Using provider " + provider.Name())
+	return generate(context.Background(), provider, cfg, pr, w)
+}
+
+// generate sends the diff in a single request when it fits in the model's context window.
+// Larger diffs are split into chunks that are summarised first, and the result is generated from the summaries.
+func generate(ctx context.Context, p Provider, cfg config.Config, pr prompt, w io.Writer) (Result, error) {
+	l := logger.GetLogger()
+	start := time.Now()
+	result := Result{Provider: p.Name(), Model: p.Model(cfg)}
+	budget := inputBudget(cfg, result.Model, pr.overhead())
+	messages := pr.messages(cfg)
+	if estimateTokens(pr.diff) > budget {
+		chunks := splitDiff(pr.diff, budget)
+		l.Info("Diff is too large for a single request, summarising it in chunks",
+			"tokens", estimateTokens(pr.diff), "budget", budget, "chunks", len(chunks))
+		summaries, usage, err := summariseChunks(ctx, p, cfg, chunks)
+		if err != nil {
+			return Result{}, err
+		}
+		result.Usage = usage
+		for round := 1; estimateTokens(strings.Join(summaries, "\n\n")) > budget; round++ {
+			if round > maxReduceRounds {
+				return Result{}, fmt.Errorf("%w: summaries still exceed %d tokens", ErrContextTooLarge, budget)
+			}
+			l.Debug("Summaries are too large, summarising them again", "round", round)
+			summaries, usage, err = summariseChunks(ctx, p, cfg, splitLines(strings.Join(summaries, "\n\n"), budget))
+			if err != nil {
+				return Result{}, err
+			}
+			result.Usage = result.Usage.add(usage)
+		}
+		messages = pr.summaryMessages(cfg, summaries)
+	}
+
+	resp, err := complete(ctx, p, cfg, messages, w)
+	if err != nil {
+		return Result{}, err
+	}
+	result.Message = resp.Text
+	result.Usage = result.Usage.add(resp.Usage)
+	result.FinishReason = resp.FinishReason
+	result.Elapsed = time.Since(start)
+	return result, nil
+}
+
+// complete sends the messages to the provider, streaming the generated text to w when it is set.
+// Errors meaning the prompt was too large or filtered are wrapped with ErrContextTooLarge or ErrContentFiltered.
+func complete(ctx context.Context, p Provider, cfg config.Config, messages []Message, w io.Writer) (Response, error) {
+	resp, err := send(ctx, p, cfg, messages, w)
+	if err != nil {
+		return Response{}, classifyError(err)
+	}
+	if resp.FinishReason == FinishContentFilter {
+		return Response{}, ErrContentFiltered
+	}
+	return resp, nil
+}
+
+func send(ctx context.Context, p Provider, cfg config.Config, messages []Message, w io.Writer) (Response, error) {
+	if w == nil {
+		return p.Generate(ctx, cfg, messages)
+	}
+	streamer, ok := p.(StreamingProvider)
+	if !ok {
+		resp, err := p.Generate(ctx, cfg, messages)
+		if err != nil {
+			return Response{}, err
+		}
+		_, err = io.WriteString(w, resp.Text)
+		return resp, err
+	}
+	var writeErr error
+	resp, err := streamer.Stream(ctx, cfg, messages, func(token string) {
+		if writeErr == nil {
+			_, writeErr = io.WriteString(w, token)
+		}
+	})
+	if err != nil {
+		return Response{}, err
+	}
+	return resp, writeErr
+}
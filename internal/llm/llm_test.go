@@ -0,0 +1,13 @@
+package llm_test
+
+import (
+	"os"
+	"testing"
+
+	"gic/internal/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger()
+	os.Exit(m.Run())
+}
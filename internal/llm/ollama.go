@@ -0,0 +1,126 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gic/internal/config"
+
+	"github.com/ollama/ollama/api"
+)
+
+// ollamaProvider generates messages with an Ollama server.
+type ollamaProvider struct{}
+
+func init() {
+	Register(ollamaProvider{})
+}
+
+// Name returns the name of the provider.
+func (ollamaProvider) Name() string {
+	return "ollama"
+}
+
+// Model returns the configured Ollama model.
+func (ollamaProvider) Model(cfg config.Config) string {
+	return cfg.ConnectionConfig.OllamaDeploymentName
+}
+
+// Validate checks the Ollama connection config.
+func (ollamaProvider) Validate(cfg config.Config) error {
+	if cfg.ConnectionConfig.OllamaAPIKey == emptyString {
+		return fmt.Errorf("OLLAMA_API_KEY environment variable not set")
+	}
+	if cfg.ConnectionConfig.OllamaAPIBase == emptyString {
+		return fmt.Errorf("OLLAMA_API_BASE environment variable not set")
+	}
+	if _, err := parseBaseURL(cfg.ConnectionConfig.OllamaAPIBase); err != nil {
+		return fmt.Errorf("OLLAMA_API_BASE is not a valid url: %w", err)
+	}
+	if cfg.ConnectionConfig.OllamaDeploymentName == emptyString {
+		return fmt.Errorf("OLLAMA_DEPLOYMENT_NAME environment variable not set")
+	}
+	return nil
+}
+
+// Generate generates a message using the Ollama service.
+func (ollamaProvider) Generate(ctx context.Context, cfg config.Config, messages []Message) (Response, error) {
+	return ollamaChat(ctx, cfg, messages, false, func(string) {})
+}
+
+// Stream streams a message from the Ollama service.
+func (ollamaProvider) Stream(
+	ctx context.Context,
+	cfg config.Config,
+	messages []Message,
+	onToken func(string),
+) (Response, error) {
+	return ollamaChat(ctx, cfg, messages, true, onToken)
+}
+
+func ollamaChat(
+	ctx context.Context,
+	cfg config.Config,
+	messages []Message,
+	stream bool,
+	onToken func(string),
+) (Response, error) {
+	client, err := newOllamaClient(cfg)
+	if err != nil {
+		return Response{}, err
+	}
+
+	req := &api.ChatRequest{
+		Model:    cfg.ConnectionConfig.OllamaDeploymentName,
+		Messages: ollamaMessages(messages),
+		Stream:   &stream,
+	}
+
+	var result Response
+	var commitMessage strings.Builder
+	respFunc := func(resp api.ChatResponse) error {
+		onToken(resp.Message.Content)
+		commitMessage.WriteString(resp.Message.Content)
+		if resp.Done {
+			result.FinishReason = resp.DoneReason
+			result.Usage = Usage{InputTokens: resp.PromptEvalCount, OutputTokens: resp.EvalCount}
+		}
+		return nil
+	}
+	if err := client.Chat(ctx, req, respFunc); err != nil {
+		return Response{}, err
+	}
+	result.Text = commitMessage.String()
+	return result, nil
+}
+
+// newOllamaClient builds a client for OLLAMA_API_BASE. OLLAMA_API_KEY is sent as a bearer token
+// so servers behind an authenticating reverse proxy can be reached.
+func newOllamaClient(cfg config.Config) (*api.Client, error) {
+	connCfg := cfg.ConnectionConfig
+	base, err := parseBaseURL(connCfg.OllamaAPIBase)
+	if err != nil {
+		return nil, err
+	}
+	rt, err := withCACert(connCfg.OllamaCACert)
+	if err != nil {
+		return nil, err
+	}
+	headers := map[string]string{}
+	for key, value := range connCfg.OllamaExtraHeaders {
+		headers[key] = value
+	}
+	if connCfg.OllamaAPIKey != emptyString {
+		headers["Authorization"] = "Bearer " + connCfg.OllamaAPIKey
+	}
+	return api.NewClient(base, newHTTPClient(cfg, rt, headers)), nil
+}
+
+func ollamaMessages(messages []Message) []api.Message {
+	olMessages := make([]api.Message, 0, len(messages))
+	for _, m := range messages {
+		olMessages = append(olMessages, api.Message{Role: m.Role, Content: m.Content})
+	}
+	return olMessages
+}
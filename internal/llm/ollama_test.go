@@ -0,0 +1,80 @@
+package llm_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gic/internal/config"
+	"gic/internal/llm"
+)
+
+const ollamaResponse = `{"model":"phi3","message":{"role":"assistant","content":"fix(llm): use OLLAMA_API_BASE"},"done":true}`
+
+func newOllamaConfig(base string) config.Config {
+	var cfg config.Config
+	cfg.LLMInstructions = "instructions"
+	cfg.ConnectionConfig.ServiceProvider = "ollama"
+	cfg.ConnectionConfig.OllamaAPIKey = "secret"
+	cfg.ConnectionConfig.OllamaAPIBase = base
+	cfg.ConnectionConfig.OllamaDeploymentName = "phi3"
+	return cfg
+}
+
+func TestOllamaUsesAPIBaseAndKey(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(ollamaResponse))
+	}))
+	defer server.Close()
+
+	cfg := newOllamaConfig(server.URL + "/gpu-box")
+	cfg.ConnectionConfig.OllamaExtraHeaders = map[string]string{"X-Team": "platform"}
+
+	result, err := llm.GenerateCommitMessage(cfg, "diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Message != "fix(llm): use OLLAMA_API_BASE" {
+		t.Fatalf("unexpected message: %q", result.Message)
+	}
+	if got.URL.Path != "/gpu-box/api/chat" {
+		t.Errorf("expected path /gpu-box/api/chat, got %s", got.URL.Path)
+	}
+	if auth := got.Header.Get("Authorization"); auth != "Bearer secret" {
+		t.Errorf("expected bearer authorization header, got %q", auth)
+	}
+	if team := got.Header.Get("X-Team"); team != "platform" {
+		t.Errorf("expected X-Team header, got %q", team)
+	}
+}
+
+type countingTransport struct {
+	requests int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestSetHTTPTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(ollamaResponse))
+	}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+	llm.SetHTTPTransport(transport)
+	defer llm.SetHTTPTransport(nil)
+
+	if _, err := llm.GenerateCommitMessage(newOllamaConfig(server.URL), "diff"); err != nil {
+		t.Fatal(err)
+	}
+	if transport.requests != 1 {
+		t.Fatalf("expected the custom transport to send 1 request, got %d", transport.requests)
+	}
+}
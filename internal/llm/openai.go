@@ -0,0 +1,157 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gic/internal/config"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// openAIProvider generates messages with the OpenAI chat completions API.
+type openAIProvider struct{}
+
+func init() {
+	Register(openAIProvider{})
+}
+
+// Name returns the name of the provider.
+func (openAIProvider) Name() string {
+	return "openai"
+}
+
+// Model returns the configured OpenAI model.
+func (openAIProvider) Model(cfg config.Config) string {
+	return cfg.ConnectionConfig.OpenAIDeploymentName
+}
+
+// Validate checks the OpenAI connection config.
+func (openAIProvider) Validate(cfg config.Config) error {
+	if cfg.ConnectionConfig.OpenAIAPIKey == emptyString {
+		return fmt.Errorf("OPENAI_API_KEY environment variable not set")
+	}
+	if cfg.ConnectionConfig.OpenAIAPIBase == emptyString {
+		return fmt.Errorf("OPENAI_API_BASE environment variable not set")
+	}
+	if _, err := parseBaseURL(cfg.ConnectionConfig.OpenAIAPIBase); err != nil {
+		return fmt.Errorf("OPENAI_API_BASE is not a valid url: %w", err)
+	}
+	return nil
+}
+
+// Generate generates a message using the OpenAI service.
+func (openAIProvider) Generate(ctx context.Context, cfg config.Config, messages []Message) (Response, error) {
+	opts, err := openAIOptions(cfg)
+	if err != nil {
+		return Response{}, err
+	}
+	client := openai.NewClient(opts...)
+	chatCompletion, err := client.Chat.Completions.New(ctx, openAIParams(cfg, messages))
+	if err != nil {
+		return Response{}, err
+	}
+	if len(chatCompletion.Choices) == 0 {
+		return Response{}, fmt.Errorf("openai returned no choices")
+	}
+	choice := chatCompletion.Choices[responseMessage]
+	return Response{
+		Text:         choice.Message.Content,
+		Usage:        openAIUsage(chatCompletion.Usage),
+		FinishReason: string(choice.FinishReason),
+	}, nil
+}
+
+// Stream streams a message from the OpenAI service.
+func (openAIProvider) Stream(
+	ctx context.Context,
+	cfg config.Config,
+	messages []Message,
+	onToken func(string),
+) (Response, error) {
+	opts, err := openAIOptions(cfg)
+	if err != nil {
+		return Response{}, err
+	}
+	client := openai.NewClient(opts...)
+	params := openAIParams(cfg, messages)
+	// The usage is sent in a last chunk without choices
+	params.StreamOptions = openai.F(openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.F(true)})
+	stream := client.Chat.Completions.NewStreaming(ctx, params)
+	defer stream.Close()
+
+	var resp Response
+	var text strings.Builder
+	for stream.Next() {
+		chunk := stream.Current()
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != emptyString {
+				onToken(choice.Delta.Content)
+				text.WriteString(choice.Delta.Content)
+			}
+			if choice.FinishReason != emptyString {
+				resp.FinishReason = string(choice.FinishReason)
+			}
+		}
+		if chunk.Usage.TotalTokens > 0 {
+			resp.Usage = openAIUsage(chunk.Usage)
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return Response{}, err
+	}
+	resp.Text = text.String()
+	return resp, nil
+}
+
+func openAIUsage(usage openai.CompletionUsage) Usage {
+	return Usage{InputTokens: int(usage.PromptTokens), OutputTokens: int(usage.CompletionTokens)}
+}
+
+func openAIParams(cfg config.Config, messages []Message) openai.ChatCompletionNewParams {
+	return openai.ChatCompletionNewParams{
+		Messages: openai.F(openAIMessages(messages)),
+		Model:    openai.F(cfg.ConnectionConfig.OpenAIDeploymentName),
+	}
+}
+
+// openAIOptions points the client at OPENAI_API_BASE so any OpenAI compatible server can be used.
+func openAIOptions(cfg config.Config) ([]option.RequestOption, error) {
+	connCfg := cfg.ConnectionConfig
+	baseURL, err := parseBaseURL(connCfg.OpenAIAPIBase)
+	if err != nil {
+		return nil, err
+	}
+	opts := []option.RequestOption{
+		option.WithBaseURL(baseURL.String()),
+		option.WithAPIKey(connCfg.OpenAIAPIKey),
+		option.WithHTTPClient(newHTTPClient(cfg, baseTransport, nil)),
+		// retries are handled by the shared transport
+		option.WithMaxRetries(0),
+	}
+	if connCfg.OpenAIOrganization != emptyString {
+		opts = append(opts, option.WithOrganization(connCfg.OpenAIOrganization))
+	}
+	if connCfg.OpenAIProject != emptyString {
+		opts = append(opts, option.WithProject(connCfg.OpenAIProject))
+	}
+	for key, value := range connCfg.OpenAIExtraHeaders {
+		opts = append(opts, option.WithHeader(key, value))
+	}
+	return opts, nil
+}
+
+func openAIMessages(messages []Message) []openai.ChatCompletionMessageParamUnion {
+	oaMessages := make([]openai.ChatCompletionMessageParamUnion, 0, len(messages))
+	for _, m := range messages {
+		switch m.Role {
+		case roleSystem:
+			oaMessages = append(oaMessages, openai.SystemMessage(m.Content))
+		default:
+			oaMessages = append(oaMessages, openai.UserMessage(m.Content))
+		}
+	}
+	return oaMessages
+}
@@ -0,0 +1,111 @@
+package llm_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gic/internal/config"
+	"gic/internal/llm"
+)
+
+type openAIRequest struct {
+	Model string `json:"model"`
+}
+
+const openAIResponse = `{
+	"id": "chatcmpl-1",
+	"object": "chat.completion",
+	"created": 1,
+	"model": "local-model",
+	"choices": [{
+		"index": 0,
+		"finish_reason": "stop",
+		"message": {"role": "assistant", "content": "feat(llm): honor OPENAI_API_BASE"}
+	}]
+}`
+
+func newOpenAIConfig(base string) config.Config {
+	var cfg config.Config
+	cfg.LLMInstructions = "instructions"
+	cfg.ConnectionConfig.ServiceProvider = "openai"
+	cfg.ConnectionConfig.OpenAIAPIKey = "test-key"
+	cfg.ConnectionConfig.OpenAIAPIBase = base
+	cfg.ConnectionConfig.OpenAIDeploymentName = "local-model"
+	return cfg
+}
+
+func TestOpenAIUsesAPIBase(t *testing.T) {
+	var got *http.Request
+	var body openAIRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request body: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(openAIResponse))
+	}))
+	defer server.Close()
+
+	cfg := newOpenAIConfig(server.URL + "/v1")
+	cfg.ConnectionConfig.OpenAIOrganization = "org-1"
+	cfg.ConnectionConfig.OpenAIProject = "proj-1"
+	cfg.ConnectionConfig.OpenAIExtraHeaders = map[string]string{"X-Team": "platform"}
+
+	result, err := llm.GenerateCommitMessage(cfg, "diff --git a/a b/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Message != "feat(llm): honor OPENAI_API_BASE" {
+		t.Fatalf("unexpected message: %q", result.Message)
+	}
+	if got == nil {
+		t.Fatal("expected request to reach the local server")
+	}
+	if got.URL.Path != "/v1/chat/completions" {
+		t.Errorf("expected path /v1/chat/completions, got %s", got.URL.Path)
+	}
+	headers := map[string]string{
+		"Authorization":       "Bearer test-key",
+		"OpenAI-Organization": "org-1",
+		"OpenAI-Project":      "proj-1",
+		"X-Team":              "platform",
+	}
+	for key, want := range headers {
+		if value := got.Header.Get(key); value != want {
+			t.Errorf("expected header %s=%q, got %q", key, want, value)
+		}
+	}
+	if body.Model != "local-model" {
+		t.Errorf("expected model local-model, got %s", body.Model)
+	}
+}
+
+func TestOpenAIAPIBaseWithTrailingSlash(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(openAIResponse))
+	}))
+	defer server.Close()
+
+	if _, err := llm.GenerateCommitMessage(newOpenAIConfig(server.URL+"/openai/v1/"), "diff"); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/openai/v1/chat/completions" {
+		t.Errorf("expected path /openai/v1/chat/completions, got %s", path)
+	}
+}
+
+func TestOpenAIValidateRejectsInvalidBase(t *testing.T) {
+	provider, err := llm.GetProvider("openai")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := provider.Validate(newOpenAIConfig("not a url")); err == nil {
+		t.Fatal("expected an error for an invalid OPENAI_API_BASE")
+	}
+}
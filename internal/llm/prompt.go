@@ -0,0 +1,142 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+
+	"gic/internal/config"
+)
+
+const (
+	roleSystem      = "system"
+	roleUser        = "user"
+	diffPrefix      = "git commit diff: "
+	pullRequestDiff = "git diff of the pull request: "
+	rangeDiff       = "git diff of the range: "
+	summaryPrefix   = "summaries of the parts of the git commit diff: "
+	guidancePrefix  = "additional guidance from the user: "
+	// chunkInstructions is the system prompt used to summarise one part of a diff that is too large for one request.
+	chunkInstructions = "You summarise one part of a larger git diff. " +
+		"List what changed and why in a few short bullet points, mentioning the files involved. " +
+		"Do not write a commit message."
+	// splitInstructions is the system prompt used to group staged files into logical commits.
+	splitInstructions = "You split staged changes into logical commits. " +
+		"The user provides the staged files and their diff. " +
+		"Group the files so every group is one self-contained change, and write a commit message for each group. " +
+		"Every file must be in exactly one group. Return ONLY a JSON array with no code fences, in commit order, " +
+		`like [{"files": ["path/a.go", "path/b.go"], "message": "feat(a): add a"}].`
+)
+
+// prompt is what the LLM is asked to generate from: the system instructions,
+// context sent before the diff, and the diff itself with the label introducing it.
+type prompt struct {
+	instructions string
+	context      string
+	diffLabel    string
+	diff         string
+}
+
+// commitPrompt asks for a commit message following llm_instructions.
+func commitPrompt(cfg config.Config, diff string) prompt {
+	return prompt{instructions: cfg.LLMInstructions, diffLabel: diffPrefix, diff: diff}
+}
+
+// pullRequestPrompt asks for a pull request description following pr_instructions,
+// giving the commit subjects and the diffstat of the branch as context.
+func pullRequestPrompt(cfg config.Config, commits []string, diffStat, diff string) prompt {
+	return prompt{
+		instructions: cfg.PRInstructions,
+		context:      commitsContext("commits in the pull request:\n", commits, diffStat),
+		diffLabel:    pullRequestDiff,
+		diff:         diff,
+	}
+}
+
+// rangePrompt asks for a summary of a revision range following llm_instructions,
+// giving the commit subjects and the diffstat of the range as context.
+func rangePrompt(cfg config.Config, commits []string, diffStat, diff string) prompt {
+	return prompt{
+		instructions: cfg.LLMInstructions,
+		context:      commitsContext("commits in the range:\n", commits, diffStat),
+		diffLabel:    rangeDiff,
+		diff:         diff,
+	}
+}
+
+// squashPrompt asks for one commit message following llm_instructions for commits that are squashed,
+// giving their messages as context.
+func squashPrompt(cfg config.Config, messages []string, diff string) prompt {
+	var context strings.Builder
+	context.WriteString("messages of the commits being squashed:\n")
+	for _, message := range messages {
+		context.WriteString("---\n" + message + "\n")
+	}
+	context.WriteString("---\n\n")
+	return prompt{instructions: cfg.LLMInstructions, context: context.String(), diffLabel: diffPrefix, diff: diff}
+}
+
+// splitPrompt asks for the staged files to be grouped into commits, with messages following llm_instructions.
+func splitPrompt(cfg config.Config, files []string, diff string) prompt {
+	var context strings.Builder
+	context.WriteString("staged files:\n")
+	for _, file := range files {
+		context.WriteString("- " + file + "\n")
+	}
+	context.WriteString("\n")
+	return prompt{
+		instructions: splitInstructions + "\n\nWrite every commit message following these instructions:\n" +
+			cfg.LLMInstructions,
+		context:   context.String(),
+		diffLabel: diffPrefix,
+		diff:      diff,
+	}
+}
+
+// commitsContext lists the commit subjects under the heading, followed by the diffstat.
+func commitsContext(heading string, commits []string, diffStat string) string {
+	var context strings.Builder
+	context.WriteString(heading)
+	for _, commit := range commits {
+		context.WriteString("- " + commit + "\n")
+	}
+	context.WriteString("\ndiffstat:\n" + diffStat + "\n\n")
+	return context.String()
+}
+
+// messages returns the system and user messages shared by every provider.
+func (p prompt) messages(cfg config.Config) []Message {
+	return []Message{
+		{Role: roleSystem, Content: p.instructions},
+		{Role: roleUser, Content: p.context + p.diffLabel + p.diff + guidance(cfg)},
+	}
+}
+
+// summaryMessages returns the messages generating the final result from the summaries of a diff
+// that was too large to be sent at once.
+func (p prompt) summaryMessages(cfg config.Config, summaries []string) []Message {
+	return []Message{
+		{Role: roleSystem, Content: p.instructions},
+		{Role: roleUser, Content: p.context + summaryPrefix + "\n\n" + strings.Join(summaries, "\n\n") + guidance(cfg)},
+	}
+}
+
+// overhead returns the text sent with every request besides the diff.
+func (p prompt) overhead() string {
+	return p.instructions + p.context + p.diffLabel
+}
+
+// guidance returns the extra direction the user gave when asking for a new message, if any.
+func guidance(cfg config.Config) string {
+	if strings.TrimSpace(cfg.Guidance) == emptyString {
+		return emptyString
+	}
+	return "\n\n" + guidancePrefix + cfg.Guidance
+}
+
+// buildChunkMessages returns the messages asking for a summary of one part of a diff.
+func buildChunkMessages(chunk string, index, total int) []Message {
+	return []Message{
+		{Role: roleSystem, Content: chunkInstructions},
+		{Role: roleUser, Content: fmt.Sprintf("part %d of %d of the git diff: %s", index, total, chunk)},
+	}
+}
@@ -0,0 +1,211 @@
+package llm_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gic/internal/config"
+	"gic/internal/llm"
+)
+
+const (
+	testInstructions = "Generate a conventional commit message."
+	testDiff         = "diff --git a/main.go b/main.go"
+)
+
+type chatMessage struct {
+	Role    string
+	Content string
+}
+
+// UnmarshalJSON accepts content either as a plain string or as a list of text parts.
+func (m *chatMessage) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	m.Role = raw.Role
+	if err := json.Unmarshal(raw.Content, &m.Content); err == nil {
+		return nil
+	}
+	var parts []struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw.Content, &parts); err != nil {
+		return err
+	}
+	for _, p := range parts {
+		m.Content += p.Text
+	}
+	return nil
+}
+
+// newCapturingServer starts a server that records the chat messages it receives and replies with response.
+func newCapturingServer(t *testing.T, response string, messages *[]chatMessage) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []chatMessage `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request body: %v", err)
+		}
+		*messages = body.Messages
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestProvidersSendTheSamePrompt(t *testing.T) {
+	tests := []struct {
+		name      string
+		response  string
+		configure func(cfg *config.Config, url string)
+	}{
+		{
+			name:     "openai",
+			response: openAIResponse,
+			configure: func(cfg *config.Config, url string) {
+				cfg.ConnectionConfig.OpenAIAPIKey = "key"
+				cfg.ConnectionConfig.OpenAIAPIBase = url
+				cfg.ConnectionConfig.OpenAIDeploymentName = "model"
+			},
+		},
+		{
+			name:     "azure",
+			response: `{"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"msg"}}]}`,
+			configure: func(cfg *config.Config, url string) {
+				cfg.ConnectionConfig.AzureAuthenticationType = "api_key"
+				cfg.ConnectionConfig.AzureOpenAIAPIKey = "key"
+				cfg.ConnectionConfig.AzureOpenAIEndpoint = url
+				cfg.ConnectionConfig.AzureOpenAIDeploymentName = "deployment"
+			},
+		},
+		{
+			name:     "ollama",
+			response: `{"model":"phi3","message":{"role":"assistant","content":"msg"},"done":true}`,
+			configure: func(cfg *config.Config, url string) {
+				cfg.ConnectionConfig.OllamaAPIKey = "key"
+				cfg.ConnectionConfig.OllamaAPIBase = url
+				cfg.ConnectionConfig.OllamaDeploymentName = "phi3"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var messages []chatMessage
+			server := newCapturingServer(t, tt.response, &messages)
+
+			var cfg config.Config
+			cfg.LLMInstructions = testInstructions
+			cfg.ConnectionConfig.ServiceProvider = tt.name
+			tt.configure(&cfg, server.URL)
+
+			if _, err := llm.GenerateCommitMessage(cfg, testDiff); err != nil {
+				t.Fatal(err)
+			}
+
+			want := []chatMessage{
+				{Role: "system", Content: testInstructions},
+				{Role: "user", Content: "git commit diff: " + testDiff},
+			}
+			if len(messages) != len(want) {
+				t.Fatalf("expected %d messages, got %d: %+v", len(want), len(messages), messages)
+			}
+			for i := range want {
+				if messages[i] != want[i] {
+					t.Errorf("message %d: expected %+v, got %+v", i, want[i], messages[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGuidanceIsAppendedToThePrompt(t *testing.T) {
+	var messages []chatMessage
+	server := newCapturingServer(t, ollamaResponse, &messages)
+
+	cfg := newOllamaConfig(server.URL)
+	cfg.Guidance = "mention the migration"
+	if _, err := llm.GenerateCommitMessage(cfg, testDiff); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "git commit diff: " + testDiff + "\n\nadditional guidance from the user: mention the migration"
+	if len(messages) != 2 || messages[1].Content != want {
+		t.Fatalf("expected user message %q, got %+v", want, messages)
+	}
+}
+
+func TestPullRequestPrompt(t *testing.T) {
+	var messages []chatMessage
+	server := newCapturingServer(t, ollamaResponse, &messages)
+
+	cfg := newOllamaConfig(server.URL)
+	cfg.PRInstructions = "Write a pull request description."
+	commits := []string{"feat: add feature", "test: cover feature"}
+	diffStat := " feature.go | 1 +\n 1 file changed, 1 insertion(+)"
+	if _, err := llm.GeneratePullRequest(cfg, commits, diffStat, testDiff); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []chatMessage{
+		{Role: "system", Content: "Write a pull request description."},
+		{Role: "user", Content: "commits in the pull request:\n- feat: add feature\n- test: cover feature\n\n" +
+			"diffstat:\n" + diffStat + "\n\ngit diff of the pull request: " + testDiff},
+	}
+	if len(messages) != len(want) {
+		t.Fatalf("expected %d messages, got %d: %+v", len(want), len(messages), messages)
+	}
+	for i := range want {
+		if messages[i] != want[i] {
+			t.Errorf("message %d: expected %+v, got %+v", i, want[i], messages[i])
+		}
+	}
+}
+
+func TestPullRequestWithoutChanges(t *testing.T) {
+	cfg := newOllamaConfig("http://127.0.0.1:1")
+	if _, err := llm.GeneratePullRequest(cfg, nil, "", ""); err == nil {
+		t.Fatal("expected an error when the branch has no changes")
+	}
+}
+
+func TestRangePrompt(t *testing.T) {
+	var messages []chatMessage
+	server := newCapturingServer(t, ollamaResponse, &messages)
+
+	cfg := newOllamaConfig(server.URL)
+	if _, err := llm.SummarizeRange(cfg, []string{"feat: add a"}, " a.go | 1 +", testDiff); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "commits in the range:\n- feat: add a\n\ndiffstat:\n a.go | 1 +\n\ngit diff of the range: " + testDiff
+	if len(messages) != 2 || messages[0].Content != cfg.LLMInstructions || messages[1].Content != want {
+		t.Fatalf("expected user message %q, got %+v", want, messages)
+	}
+}
+
+func TestSquashPrompt(t *testing.T) {
+	var messages []chatMessage
+	server := newCapturingServer(t, ollamaResponse, &messages)
+
+	cfg := newOllamaConfig(server.URL)
+	if _, err := llm.GenerateSquashMessage(cfg, []string{"wip", "fix b\n\nforgot the file"}, testDiff); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "messages of the commits being squashed:\n---\nwip\n---\nfix b\n\nforgot the file\n---\n\n" +
+		"git commit diff: " + testDiff
+	if len(messages) != 2 || messages[1].Content != want {
+		t.Fatalf("expected user message %q, got %+v", want, messages)
+	}
+}
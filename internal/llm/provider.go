@@ -0,0 +1,80 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"gic/internal/config"
+)
+
+// Message is a single chat message sent to a provider.
+type Message struct {
+	Role    string
+	Content string
+}
+
+// Provider is implemented by every LLM service gic can generate messages with.
+type Provider interface {
+	// Name returns the SERVICE_PROVIDER value that selects the provider.
+	Name() string
+	// Model returns the model or deployment the provider is configured to use.
+	Model(cfg config.Config) string
+	// Validate checks that the connection config has everything the provider needs.
+	Validate(cfg config.Config) error
+	// Generate sends the messages to the LLM and returns the generated text with its usage and finish reason.
+	Generate(ctx context.Context, cfg config.Config, messages []Message) (Response, error)
+}
+
+// StreamingProvider is implemented by providers that can stream the generated text as it arrives.
+type StreamingProvider interface {
+	Provider
+	// Stream sends the messages to the LLM, calls onToken with every piece of text as it is received
+	// and returns the whole generated text with its usage and finish reason.
+	Stream(ctx context.Context, cfg config.Config, messages []Message, onToken func(string)) (Response, error)
+}
+
+var providers = map[string]Provider{}
+
+// Register makes a provider available under its name and registers its validation with the config package.
+// It is meant to be called from the init function of the file implementing the provider.
+func Register(p Provider) {
+	providers[p.Name()] = p
+	config.RegisterProvider(p.Name(), p.Validate)
+}
+
+// GetProvider returns the provider registered under the given name.
+func GetProvider(name string) (Provider, error) {
+	p, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported connection type: %s. options are %s", name, strings.Join(providerNames(), ", "))
+	}
+	return p, nil
+}
+
+func providerNames() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseBaseURL parses an API base url and makes sure it ends with a slash,
+// so relative API paths are appended to it instead of replacing its last segment.
+func parseBaseURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == emptyString || u.Host == emptyString {
+		return nil, fmt.Errorf("url must include a scheme and host: %s", raw)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return u, nil
+}
@@ -0,0 +1,93 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Normalised finish reasons. Providers map their own values to these where they have an equivalent,
+// and report anything else as they received it.
+const (
+	FinishStop          = "stop"
+	FinishLength        = "length"
+	FinishContentFilter = "content_filter"
+)
+
+var (
+	// ErrNoChanges is returned when there is nothing to describe, e.g. no staged changes.
+	ErrNoChanges = errors.New("no changes to describe")
+	// ErrContextTooLarge is returned when the prompt does not fit in the context window of the model,
+	// even after summarising the diff in chunks.
+	ErrContextTooLarge = errors.New("the diff does not fit in the context window of the model")
+	// ErrContentFiltered is returned when the provider blocked the prompt or the response.
+	ErrContentFiltered = errors.New("the provider's content filter blocked the request")
+)
+
+// contextTooLargeMessages are parts of the error messages providers return when the prompt is too long.
+var contextTooLargeMessages = []string{
+	"context_length_exceeded",
+	"maximum context length",
+	"context window",
+	"prompt is too long",
+	"input is too long",
+	"too many tokens",
+}
+
+// contentFilterMessages are parts of the error messages providers return when a content filter blocked the prompt.
+var contentFilterMessages = []string{
+	"content_filter",
+	"content management policy",
+}
+
+// Usage is the number of tokens the requests for a result consumed.
+type Usage struct {
+	InputTokens  int
+	OutputTokens int
+}
+
+// add returns the sum of both usages.
+func (u Usage) add(other Usage) Usage {
+	return Usage{InputTokens: u.InputTokens + other.InputTokens, OutputTokens: u.OutputTokens + other.OutputTokens}
+}
+
+// Response is what a provider returns for a single request.
+type Response struct {
+	Text         string
+	Usage        Usage
+	FinishReason string
+}
+
+// Result is a generated message with the details of how it was generated.
+type Result struct {
+	Message  string
+	Provider string
+	Model    string
+	// Usage adds up every request made for the message, including the summaries of large diffs.
+	Usage Usage
+	// FinishReason is why the model stopped generating the message, one of the Finish values when it is known.
+	FinishReason string
+	// Elapsed is how long generating the message took.
+	Elapsed time.Duration
+}
+
+// classifyError wraps provider errors that mean the prompt was too large or was filtered
+// with ErrContextTooLarge or ErrContentFiltered.
+func classifyError(err error) error {
+	if err == nil || errors.Is(err, ErrContextTooLarge) || errors.Is(err, ErrContentFiltered) {
+		return err
+	}
+	message := strings.ToLower(err.Error())
+	for _, part := range contextTooLargeMessages {
+		if strings.Contains(message, part) {
+			return fmt.Errorf("%w: %w", ErrContextTooLarge, err)
+		}
+	}
+	for _, part := range contentFilterMessages {
+		if strings.Contains(message, part) {
+			return fmt.Errorf("%w: %w", ErrContentFiltered, err)
+		}
+	}
+	return err
+}
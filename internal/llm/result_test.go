@@ -0,0 +1,128 @@
+package llm_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gic/internal/config"
+	"gic/internal/llm"
+)
+
+// newJSONServer starts a server replying to every request with the status and body.
+func newJSONServer(t *testing.T, status int, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		config func(url string) config.Config
+		want   llm.Result
+	}{
+		{
+			name: "openai",
+			body: `{"choices":[{"index":0,"finish_reason":"length","message":{"role":"assistant","content":"feat: a"}}],` +
+				`"usage":{"prompt_tokens":12,"completion_tokens":3,"total_tokens":15}}`,
+			config: newOpenAIConfig,
+			want: llm.Result{Message: "feat: a", Provider: "openai", Model: "local-model",
+				Usage: llm.Usage{InputTokens: 12, OutputTokens: 3}, FinishReason: llm.FinishLength},
+		},
+		{
+			name: "anthropic",
+			body: `{"content":[{"type":"text","text":"feat: a"}],"stop_reason":"end_turn",` +
+				`"usage":{"input_tokens":20,"output_tokens":4}}`,
+			config: newAnthropicConfig,
+			want: llm.Result{Message: "feat: a", Provider: "anthropic", Model: "claude-test",
+				Usage: llm.Usage{InputTokens: 20, OutputTokens: 4}, FinishReason: llm.FinishStop},
+		},
+		{
+			name: "ollama",
+			body: `{"model":"phi3","message":{"role":"assistant","content":"feat: a"},"done":true,` +
+				`"done_reason":"stop","prompt_eval_count":30,"eval_count":5}`,
+			config: newOllamaConfig,
+			want: llm.Result{Message: "feat: a", Provider: "ollama", Model: "phi3",
+				Usage: llm.Usage{InputTokens: 30, OutputTokens: 5}, FinishReason: llm.FinishStop},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newJSONServer(t, http.StatusOK, tt.body)
+			result, err := llm.GenerateCommitMessage(tt.config(server.URL), testDiff)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Elapsed <= 0 {
+				t.Errorf("expected the elapsed time to be set, got %v", result.Elapsed)
+			}
+			result.Elapsed = 0
+			if result != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, result)
+			}
+		})
+	}
+}
+
+func TestNoChanges(t *testing.T) {
+	for _, diff := range []string{"", "\n"} {
+		if _, err := llm.GenerateCommitMessage(newOllamaConfig("http://127.0.0.1:1"), diff); !errors.Is(err, llm.ErrNoChanges) {
+			t.Errorf("expected ErrNoChanges for %q, got %v", diff, err)
+		}
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{
+			name:   "context too large",
+			status: http.StatusBadRequest,
+			body: `{"error":{"message":"This model's maximum context length is 8192 tokens.",` +
+				`"type":"invalid_request_error","code":"context_length_exceeded"}}`,
+			want: llm.ErrContextTooLarge,
+		},
+		{
+			name:   "filtered prompt",
+			status: http.StatusBadRequest,
+			body: `{"error":{"message":"The response was filtered due to the prompt triggering ` +
+				`content management policy.","type":null,"code":"content_filter"}}`,
+			want: llm.ErrContentFiltered,
+		},
+		{
+			name:   "filtered response",
+			status: http.StatusOK,
+			body:   `{"choices":[{"index":0,"finish_reason":"content_filter","message":{"role":"assistant","content":""}}]}`,
+			want:   llm.ErrContentFiltered,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newJSONServer(t, tt.status, tt.body)
+			_, err := llm.GenerateCommitMessage(newOpenAIConfig(server.URL), testDiff)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestGeminiBlockedPrompt(t *testing.T) {
+	server := newJSONServer(t, http.StatusOK, `{"promptFeedback":{"blockReason":"SAFETY"}}`)
+	cfg := newGeminiConfig(server.URL)
+	if _, err := llm.GenerateCommitMessage(cfg, testDiff); !errors.Is(err, llm.ErrContentFiltered) {
+		t.Fatalf("expected ErrContentFiltered, got %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gic/internal/config"
+	"gic/internal/logger"
+)
+
+const (
+	backoffJitter  = 0.5
+	backoffFactor  = 2
+	maxDrainedBody = 4096
+)
+
+// retryTransport retries requests that failed with a transient error, waiting with exponential backoff
+// or for as long as the Retry-After header asks, and limits every attempt to the configured timeout.
+type retryTransport struct {
+	next       http.RoundTripper
+	timeout    time.Duration
+	maxRetries int
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+func newRetryTransport(cfg config.Config, next http.RoundTripper) *retryTransport {
+	return &retryTransport{
+		next:       next,
+		timeout:    cfg.Timeout,
+		maxRetries: max(cfg.MaxRetries, 0),
+		backoff:    cfg.RetryBackoff,
+		maxBackoff: cfg.RetryMaxBackoff,
+	}
+}
+
+// RoundTrip sends the request, retrying it on connection errors, timeouts, 408, 429 and 5xx responses.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	l := logger.GetLogger()
+	for attempt := 0; ; attempt++ {
+		attemptReq, cancel, err := t.newAttempt(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := t.next.RoundTrip(attemptReq)
+		if attempt >= t.maxRetries || !t.shouldRetry(req, resp, err) {
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		delay := t.delay(attempt, resp)
+		if resp != nil {
+			l.Warn("LLM request failed, retrying", "status", resp.StatusCode, "attempt", attempt+1, "delay", delay)
+			discardBody(resp)
+		} else {
+			l.Warn("LLM request failed, retrying", "error", err, "attempt", attempt+1, "delay", delay)
+		}
+		cancel()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// discardBody drains and closes the body of a response that is retried, so its connection can be reused.
+func discardBody(resp *http.Response) {
+	l := logger.GetLogger()
+	if _, err := io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainedBody)); err != nil {
+		l.Debug("Unable to drain the response body before retrying", "error", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		l.Debug("Unable to close the response body before retrying", "error", err)
+	}
+}
+
+// newAttempt clones the request with a fresh body and a context limited to the request timeout.
+func (t *retryTransport) newAttempt(req *http.Request, attempt int) (*http.Request, context.CancelFunc, error) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if t.timeout > 0 {
+		ctx, cancel = context.WithTimeout(req.Context(), t.timeout)
+	} else {
+		ctx, cancel = context.WithCancel(req.Context())
+	}
+	attemptReq := req.Clone(ctx)
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, nil, err
+		}
+		attemptReq.Body = body
+	}
+	return attemptReq, cancel, nil
+}
+
+// shouldRetry reports whether a failed attempt is worth repeating. Requests whose body can not be
+// replayed and requests cancelled by the caller are never retried.
+func (*retryTransport) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if req.Context().Err() != nil {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	default:
+		return resp.StatusCode >= http.StatusInternalServerError
+	}
+}
+
+// delay returns how long to wait before the next attempt. Retry-After headers take precedence over
+// the exponential backoff, but never make gic wait longer than retry_max_backoff.
+func (t *retryTransport) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := retryAfter(resp.Header); ok {
+			if t.maxBackoff > 0 {
+				return min(d, t.maxBackoff)
+			}
+			return d
+		}
+	}
+	d := t.backoff
+	for i := 0; i < attempt && d < t.maxBackoff; i++ {
+		d *= backoffFactor
+	}
+	if t.maxBackoff > 0 && d > t.maxBackoff {
+		d = t.maxBackoff
+	}
+	// #nosec G404 -- the jitter only spreads retries, it does not need a secure random source
+	jitter := 1 - backoffJitter*rand.Float64()
+	return time.Duration(float64(d) * jitter)
+}
+
+// retryAfter parses the retry-after-ms header used by OpenAI and Azure, and the standard Retry-After
+// header given either in seconds or as an HTTP date.
+func retryAfter(header http.Header) (time.Duration, bool) {
+	if ms, err := strconv.Atoi(header.Get("retry-after-ms")); err == nil && ms >= 0 {
+		return time.Duration(ms) * time.Millisecond, true
+	}
+	value := header.Get("Retry-After")
+	if value == emptyString {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// cancelOnClose releases the context of an attempt once its response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the attempt's context.
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
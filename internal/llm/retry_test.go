@@ -0,0 +1,168 @@
+package llm_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gic/internal/config"
+	"gic/internal/llm"
+)
+
+// newFlakyServer fails the first failures requests with fail and answers the following ones with response.
+func newFlakyServer(t *testing.T, failures int32, fail http.HandlerFunc, response string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			fail(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func withRetries(cfg config.Config) config.Config {
+	cfg.MaxRetries = 3
+	cfg.RetryBackoff = 10 * time.Millisecond
+	cfg.RetryMaxBackoff = 50 * time.Millisecond
+	return cfg
+}
+
+func status(code int) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(`{"error":"` + http.StatusText(code) + `"}`))
+	}
+}
+
+// hang never answers, until the client gives up on the request.
+func hang(_ http.ResponseWriter, r *http.Request) {
+	// reading the body lets the server notice when the client closes the connection
+	_, _ = io.Copy(io.Discard, r.Body)
+	select {
+	case <-r.Context().Done():
+	case <-time.After(5 * time.Second):
+	}
+}
+
+func TestRetriesTransientErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config func(url string) config.Config
+		resp   string
+	}{
+		{name: "openai", config: newOpenAIConfig, resp: openAIResponse},
+		{name: "ollama", config: newOllamaConfig, resp: ollamaResponse},
+		{name: "anthropic", config: newAnthropicConfig, resp: anthropicResponse},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := newFlakyServer(t, 2, status(http.StatusServiceUnavailable), tt.resp)
+
+			if _, err := llm.GenerateCommitMessage(withRetries(tt.config(server.URL)), testDiff); err != nil {
+				t.Fatal(err)
+			}
+			if got := requests.Load(); got != 3 {
+				t.Fatalf("expected 3 requests, got %d", got)
+			}
+		})
+	}
+}
+
+func TestRetryGivesUpAfterMaxRetries(t *testing.T) {
+	server, requests := newFlakyServer(t, 10, status(http.StatusBadGateway), ollamaResponse)
+
+	_, err := llm.GenerateCommitMessage(withRetries(newOllamaConfig(server.URL)), testDiff)
+	if err == nil {
+		t.Fatal("expected an error once the retries are exhausted")
+	}
+	if got := requests.Load(); got != 4 {
+		t.Fatalf("expected 1 request and 3 retries, got %d requests", got)
+	}
+}
+
+func TestNoRetryOnClientErrors(t *testing.T) {
+	server, requests := newFlakyServer(t, 10, status(http.StatusBadRequest), anthropicResponse)
+
+	_, err := llm.GenerateCommitMessage(withRetries(newAnthropicConfig(server.URL)), testDiff)
+	var statusErr *llm.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected a 400 StatusError, got %v", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("expected a single request, got %d", got)
+	}
+}
+
+func TestRetryAfterIsRespected(t *testing.T) {
+	server, requests := newFlakyServer(t, 1, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}, anthropicResponse)
+
+	cfg := withRetries(newAnthropicConfig(server.URL))
+	cfg.RetryMaxBackoff = 2 * time.Second
+	start := time.Now()
+	if _, err := llm.GenerateCommitMessage(cfg, testDiff); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("expected to wait for Retry-After, only waited %s", elapsed)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Fatalf("expected 2 requests, got %d", got)
+	}
+}
+
+func TestRetryAfterIsCappedAtMaxBackoff(t *testing.T) {
+	server, requests := newFlakyServer(t, 1, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}, anthropicResponse)
+
+	start := time.Now()
+	if _, err := llm.GenerateCommitMessage(withRetries(newAnthropicConfig(server.URL)), testDiff); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected Retry-After to be capped at retry_max_backoff, waited %s", elapsed)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Fatalf("expected 2 requests, got %d", got)
+	}
+}
+
+func TestTimeoutRetriesHungRequests(t *testing.T) {
+	server, requests := newFlakyServer(t, 1, hang, ollamaResponse)
+
+	cfg := withRetries(newOllamaConfig(server.URL))
+	cfg.Timeout = 100 * time.Millisecond
+	if _, err := llm.GenerateCommitMessage(cfg, testDiff); err != nil {
+		t.Fatal(err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Fatalf("expected the hung request to be retried, got %d requests", got)
+	}
+}
+
+func TestTimeoutFailsWithoutRetries(t *testing.T) {
+	server, _ := newFlakyServer(t, 10, hang, ollamaResponse)
+
+	cfg := newOllamaConfig(server.URL)
+	cfg.Timeout = 100 * time.Millisecond
+	start := time.Now()
+	if _, err := llm.GenerateCommitMessage(cfg, testDiff); err == nil {
+		t.Fatal("expected the request to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected the request to be cancelled after the timeout, took %s", elapsed)
+	}
+}
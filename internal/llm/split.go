@@ -0,0 +1,99 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gic/internal/config"
+	"gic/internal/logger"
+)
+
+// SplitGroup is a set of staged files that is committed together with its message.
+type SplitGroup struct {
+	Files   []string `json:"files"`
+	Message string   `json:"message"`
+}
+
+// SplitChanges asks the LLM to group the staged files into logical commits, each with its own message.
+// Every file ends up in exactly one group: files the LLM left out are added to the last group.
+func SplitChanges(cfg config.Config, files []string, diff string) ([]SplitGroup, error) {
+	l := logger.GetLogger()
+	l.Info("Grouping staged changes into commits")
+	if len(files) == 0 {
+		return nil, ErrNoChanges
+	}
+
+	provider, err := GetProvider(cfg.ConnectionConfig.ServiceProvider)
+	if err != nil {
+		return nil, err
+	}
+	l.Debug("Using provider " + provider.Name())
+	result, err := generate(context.Background(), provider, cfg, splitPrompt(cfg, files, diff), nil)
+	if err != nil {
+		return nil, err
+	}
+	return parseGroups(result.Message, files)
+}
+
+// parseGroups reads the groups from the LLM response and checks them against the staged files.
+func parseGroups(text string, files []string) ([]SplitGroup, error) {
+	text = strings.TrimSpace(text)
+	// Models sometimes wrap the JSON in a code fence or add a sentence around it
+	start, end := strings.Index(text, "["), strings.LastIndex(text, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("expected a JSON array of groups, got: %s", text)
+	}
+	var groups []SplitGroup
+	if err := json.Unmarshal([]byte(text[start:end+1]), &groups); err != nil {
+		return nil, fmt.Errorf("parsing the groups: %w", err)
+	}
+	return checkGroups(groups, files)
+}
+
+// checkGroups keeps every staged file in the first group listing it, drops the groups left without files,
+// and adds the files the LLM left out to the last group.
+func checkGroups(groups []SplitGroup, files []string) ([]SplitGroup, error) {
+	assigned := map[string]bool{}
+	result := make([]SplitGroup, 0, len(groups))
+	for _, group := range groups {
+		groupFiles, err := assignFiles(group.Files, files, assigned)
+		if err != nil {
+			return nil, err
+		}
+		if len(groupFiles) == 0 {
+			continue
+		}
+		if strings.TrimSpace(group.Message) == emptyString {
+			return nil, fmt.Errorf("the LLM returned no message for %s", strings.Join(groupFiles, ", "))
+		}
+		result = append(result, SplitGroup{Files: groupFiles, Message: strings.TrimSpace(group.Message)})
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("the LLM returned no groups")
+	}
+	for _, file := range files {
+		if !assigned[file] {
+			last := &result[len(result)-1]
+			last.Files = append(last.Files, file)
+		}
+	}
+	return result, nil
+}
+
+// assignFiles returns the files of a group that no earlier group has, and marks them as assigned.
+func assignFiles(groupFiles, files []string, assigned map[string]bool) ([]string, error) {
+	var unassigned []string
+	for _, file := range groupFiles {
+		if !slices.Contains(files, file) {
+			return nil, fmt.Errorf("the LLM grouped %s, which is not staged", file)
+		}
+		if !assigned[file] {
+			assigned[file] = true
+			unassigned = append(unassigned, file)
+		}
+	}
+	return unassigned, nil
+}
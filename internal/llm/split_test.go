@@ -0,0 +1,60 @@
+package llm_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gic/internal/llm"
+)
+
+// ollamaText returns an Ollama chat response with the text as message content.
+func ollamaText(t *testing.T, text string) string {
+	t.Helper()
+	content, err := json.Marshal(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return `{"model":"phi3","message":{"role":"assistant","content":` + string(content) + `},"done":true}`
+}
+
+func TestSplitChanges(t *testing.T) {
+	response := "```json\n" + `[{"files": ["a.go", "b.go"], "message": "feat: add a and b"},` +
+		`{"files": ["README.md", "a.go"], "message": "docs: update readme"}]` + "\n```"
+	var messages []chatMessage
+	server := newCapturingServer(t, ollamaText(t, response), &messages)
+
+	groups, err := llm.SplitChanges(newOllamaConfig(server.URL), []string{"README.md", "a.go", "b.go", "c.go"}, testDiff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %+v", groups)
+	}
+	if got := strings.Join(groups[0].Files, "|"); got != "a.go|b.go" {
+		t.Errorf("unexpected files in the first group %q", got)
+	}
+	if got := strings.Join(groups[1].Files, "|"); got != "README.md|c.go" {
+		t.Errorf("expected duplicates dropped and left out files added to the last group, got %q", got)
+	}
+	if !strings.HasPrefix(messages[1].Content, "staged files:\n- README.md\n- a.go\n- b.go\n- c.go\n\n") {
+		t.Errorf("expected the staged files in the prompt, got %q", messages[1].Content)
+	}
+}
+
+func TestSplitChangesRejectsInvalidGroups(t *testing.T) {
+	for name, response := range map[string]string{
+		"not json":      "feat: add a",
+		"unknown file":  `[{"files": ["x.go"], "message": "feat: add x"}]`,
+		"empty message": `[{"files": ["a.go"], "message": ""}]`,
+		"no groups":     `[]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var messages []chatMessage
+			server := newCapturingServer(t, ollamaText(t, response), &messages)
+			if _, err := llm.SplitChanges(newOllamaConfig(server.URL), []string{"a.go"}, testDiff); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package llm_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gic/internal/config"
+	"gic/internal/llm"
+)
+
+var streamTokens = []string{"feat", "(llm): ", "stream ", "tokens"}
+
+func TestStreamCommitMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		event     func(token string) string
+		done      string
+		configure func(cfg *config.Config, url string)
+	}{
+		{
+			name: "openai",
+			event: func(token string) string {
+				return fmt.Sprintf(`data: {"id":"1","object":"chat.completion.chunk","created":1,"model":"m",`+
+					`"choices":[{"index":0,"delta":{"content":%q}}]}`+"\n\n", token)
+			},
+			done: "data: [DONE]\n\n",
+			configure: func(cfg *config.Config, url string) {
+				cfg.ConnectionConfig.OpenAIAPIKey = "key"
+				cfg.ConnectionConfig.OpenAIAPIBase = url
+				cfg.ConnectionConfig.OpenAIDeploymentName = "model"
+			},
+		},
+		{
+			name: "azure",
+			event: func(token string) string {
+				return fmt.Sprintf(`data: {"choices":[{"index":0,"delta":{"content":%q}}]}`+"\n\n", token)
+			},
+			done: "data: [DONE]\n\n",
+			configure: func(cfg *config.Config, url string) {
+				cfg.ConnectionConfig.AzureAuthenticationType = "api_key"
+				cfg.ConnectionConfig.AzureOpenAIAPIKey = "key"
+				cfg.ConnectionConfig.AzureOpenAIEndpoint = url
+				cfg.ConnectionConfig.AzureOpenAIDeploymentName = "deployment"
+			},
+		},
+		{
+			name: "ollama",
+			event: func(token string) string {
+				return fmt.Sprintf(`{"model":"phi3","message":{"role":"assistant","content":%q},"done":false}`+"\n", token)
+			},
+			configure: func(cfg *config.Config, url string) {
+				cfg.ConnectionConfig.OllamaAPIKey = "key"
+				cfg.ConnectionConfig.OllamaAPIBase = url
+				cfg.ConnectionConfig.OllamaDeploymentName = "phi3"
+			},
+		},
+		{
+			name: "anthropic",
+			event: func(token string) string {
+				return fmt.Sprintf("event: content_block_delta\n"+
+					`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":%q}}`+"\n\n", token)
+			},
+			configure: func(cfg *config.Config, url string) {
+				cfg.ConnectionConfig.AnthropicAPIKey = "key"
+				cfg.ConnectionConfig.AnthropicBaseURL = url
+				cfg.ConnectionConfig.AnthropicModel = "claude-test"
+				cfg.ConnectionConfig.AnthropicMaxTokens = 64
+			},
+		},
+		{
+			name: "gemini",
+			event: func(token string) string {
+				return fmt.Sprintf(`data: {"candidates":[{"content":{"role":"model","parts":[{"text":%q}]}}]}`+"\n\n", token)
+			},
+			configure: func(cfg *config.Config, url string) {
+				cfg.ConnectionConfig.GeminiAPIKey = "key"
+				cfg.ConnectionConfig.GeminiAPIBase = url
+				cfg.ConnectionConfig.GeminiModel = "gemini-test"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				for _, token := range streamTokens {
+					_, _ = w.Write([]byte(tt.event(token)))
+					w.(http.Flusher).Flush()
+				}
+				_, _ = w.Write([]byte(tt.done))
+			}))
+			defer server.Close()
+
+			var cfg config.Config
+			cfg.LLMInstructions = testInstructions
+			cfg.ConnectionConfig.ServiceProvider = tt.name
+			tt.configure(&cfg, server.URL)
+
+			var out strings.Builder
+			result, err := llm.StreamCommitMessage(cfg, testDiff, &out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := strings.Join(streamTokens, "")
+			if result.Message != want {
+				t.Errorf("expected message %q, got %q", want, result.Message)
+			}
+			if out.String() != want {
+				t.Errorf("expected streamed output %q, got %q", want, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package llm
+
+import (
+	"strings"
+
+	"gic/internal/config"
+)
+
+const (
+	// charsPerToken is a rough average for English text and source code across the supported tokenizers.
+	charsPerToken        = 4
+	defaultContextWindow = 8192
+	responseTokenReserve = 1024
+	minInputTokens       = 512
+)
+
+// contextWindows maps model name prefixes to the size of their context window in tokens.
+// The longest matching prefix wins, so specific versions can override a model family.
+var contextWindows = map[string]int{
+	"gpt-4o":        128000,
+	"gpt-4-turbo":   128000,
+	"gpt-4":         8192,
+	"gpt-3.5-turbo": 16385,
+	"o1":            128000,
+	"claude":        200000,
+	"gemini":        32768,
+	"gemini-1.5":    1000000,
+	"gemini-2":      1000000,
+	"phi3":          4096,
+	"phi3.5":        128000,
+	"llama3":        8192,
+	"llama3.1":      128000,
+	"llama3.2":      128000,
+	"mistral":       32768,
+	"qwen2.5":       32768,
+}
+
+// estimateTokens returns an approximation of the number of tokens in text.
+func estimateTokens(text string) int {
+	return (len(text) + charsPerToken - 1) / charsPerToken
+}
+
+// contextWindow returns the context window of the model, or a conservative default for unknown models.
+func contextWindow(model string) int {
+	model = strings.ToLower(model)
+	window, matched := defaultContextWindow, emptyString
+	for prefix, size := range contextWindows {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(matched) {
+			window, matched = size, prefix
+		}
+	}
+	return window
+}
+
+// inputBudget returns how many tokens of diff fit in a single request, leaving room for the
+// rest of the prompt and the response. max_input_tokens in the config overrides the model's context window.
+func inputBudget(cfg config.Config, model, overhead string) int {
+	window := cfg.MaxInputTokens
+	if window <= 0 {
+		window = contextWindow(model)
+	}
+	budget := window - estimateTokens(overhead) - responseTokenReserve
+	if budget < minInputTokens {
+		return minInputTokens
+	}
+	return budget
+}
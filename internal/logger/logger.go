@@ -3,6 +3,7 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"runtime"
@@ -10,39 +11,70 @@ import (
 
 const infomationStack = 3
 
+// Log formats supported by SetFormat.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
 // Logger represents the logger structure
 type Logger struct {
 	logger *slog.Logger
 }
 
-var l *Logger
+var (
+	l      *Logger
+	level  slog.LevelVar
+	format = FormatText
+	// Logs go to stderr so stdout only carries the generated output
+	output io.Writer = os.Stderr
+)
 
-// InitLogger initializes the logger with a text handler
+// InitLogger initializes the logger with a text handler writing to stderr
 func InitLogger() {
-	l = &Logger{
-		logger: slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-			Level:     slog.LevelInfo,
-			AddSource: false, // This adds the filename and line number
-		})),
-	}
+	level.Set(slog.LevelInfo)
+	format = FormatText
+	output = os.Stderr
+	l = &Logger{logger: newLogger()}
 }
 
 // SetLogLevel sets the log level of the logger
-func SetLogLevel(level string) {
-	var logLevel slog.Level
-	switch level {
+func SetLogLevel(lvl string) {
+	switch lvl {
 	case "debug":
-		logLevel = slog.LevelDebug
+		level.Set(slog.LevelDebug)
 	case "info":
-		logLevel = slog.LevelInfo
+		level.Set(slog.LevelInfo)
 	default:
-		logLevel = slog.LevelInfo
+		level.Set(slog.LevelInfo)
 	}
+}
 
-	l.logger = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level:     logLevel,
-		AddSource: false, // Add source for filename and line number
-	}))
+// SetFormat switches the logger between text and json lines.
+func SetFormat(f string) error {
+	if f != FormatText && f != FormatJSON {
+		return fmt.Errorf("log format must be %s or %s. got: %s", FormatText, FormatJSON, f)
+	}
+	format = f
+	l.logger = newLogger()
+	return nil
+}
+
+// SetOutput makes the logger write to w instead of stderr.
+func SetOutput(w io.Writer) {
+	output = w
+	l.logger = newLogger()
+}
+
+func newLogger() *slog.Logger {
+	opts := &slog.HandlerOptions{
+		Level:     &level,
+		AddSource: false, // the source is added to the message in debug mode
+	}
+	if format == FormatJSON {
+		return slog.New(slog.NewJSONHandler(output, opts))
+	}
+	return slog.New(slog.NewTextHandler(output, opts))
 }
 
 func getCallerInfo() string {
@@ -80,7 +112,11 @@ func (l *Logger) Warn(msg string, keysAndValues ...any) {
 	l.logger.Warn(generateLogMessage(msg), keysAndValues...)
 }
 
+// generateLogMessage prefixes the message with the file and line it was logged from when debugging.
 func generateLogMessage(msg string) string {
+	if level.Level() > slog.LevelDebug {
+		return msg
+	}
 	callerInfo := getCallerInfo()
 	return fmt.Sprintf("[%s] %s", callerInfo, msg)
 }
@@ -0,0 +1,54 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gic/internal/logger"
+)
+
+func TestFormats(t *testing.T) {
+	logger.InitLogger()
+	var out bytes.Buffer
+	logger.SetOutput(&out)
+
+	logger.GetLogger().Info("text line", "key", "value")
+	if got := out.String(); !strings.Contains(got, `msg="text line" key=value`) {
+		t.Errorf("expected a text line, got %q", got)
+	}
+
+	out.Reset()
+	if err := logger.SetFormat(logger.FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	logger.GetLogger().Info("json line")
+	var line struct {
+		Msg string `json:"msg"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &line); err != nil || line.Msg != "json line" {
+		t.Errorf("expected a json line, got %q", out.String())
+	}
+
+	if err := logger.SetFormat("xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestLevels(t *testing.T) {
+	logger.InitLogger()
+	var out bytes.Buffer
+	logger.SetOutput(&out)
+
+	logger.GetLogger().Debug("hidden")
+	if out.Len() != 0 {
+		t.Errorf("expected debug logs to be hidden by default, got %q", out.String())
+	}
+
+	logger.SetLogLevel("debug")
+	logger.GetLogger().Debug("shown")
+	if got := out.String(); !strings.Contains(got, "logger_test.go") || !strings.Contains(got, "shown") {
+		t.Errorf("expected the debug log with its source, got %q", got)
+	}
+}
@@ -2,6 +2,8 @@
 package main
 
 import (
+	"os"
+
 	"gic/cmd"
 	"gic/internal/logger"
 )
@@ -13,10 +15,7 @@ var (
 
 func main() {
 	logger.InitLogger()
-	l := logger.GetLogger()
-	err := cmd.Execute(version, commit)
-	if err != nil {
-		l.Error("failed to execute command", "error", err)
-		panic(err)
+	if err := cmd.Execute(version, commit); err != nil {
+		os.Exit(cmd.HandleError(os.Stderr, err))
 	}
 }